@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/daystram/caroline/internal/audio"
+	"github.com/daystram/caroline/internal/config"
+	"github.com/daystram/caroline/internal/logger"
+)
+
+// log is replaced once the flags are parsed.
+var log = logger.New(os.Stderr, logger.LevelInfo, logger.FormatLogfmt)
+
+func main() {
+	err := Main(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOk)
+	}
+	if err != nil {
+		log.Error("init: failed", "err", err)
+		os.Exit(exitErr)
+	}
+
+	os.Exit(exitOk)
+}
+
+// Main runs an audio worker, playing audio for bots started with AUDIO_WORKERS pointing to its socket.
+func Main(args []string) error {
+	fs := flag.NewFlagSet("caroline-audio", flag.ContinueOnError)
+	socket := fs.String("socket", envOr("AUDIO_WORKER_SOCKET", "caroline-audio.sock"), "Unix socket path to listen on")
+	logLevel := fs.String("log-level", envOr("LOG_LEVEL", "info"), "Log level (debug, info, warn, error)")
+	logFormat := fs.String("log-format", envOr("LOG_FORMAT", "logfmt"), "Log format (logfmt, json)")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	level, err := logger.ParseLevel(*logLevel)
+	if err != nil {
+		return err
+	}
+	format, err := logger.ParseFormat(*logFormat)
+	if err != nil {
+		return err
+	}
+	log = logger.New(os.Stderr, level, format)
+	log.Info("init: starting", "version", config.Version())
+
+	// a socket left behind by a crashed worker blocks listening
+	_ = os.Remove(*socket)
+	l, err := net.Listen("unix", *socket)
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(*socket, 0o660)
+	if err != nil {
+		_ = l.Close()
+		return err
+	}
+
+	w := audio.NewWorker(log)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- w.Serve(l)
+	}()
+	log.Info("init: worker started", "socket", *socket)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	select {
+	case <-stop:
+	case err = <-serveErr:
+	}
+
+	log.Info("exit: worker stopping")
+	_ = l.Close()
+	w.Close()
+	return err
+}
+
+func envOr(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
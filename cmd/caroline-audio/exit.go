@@ -0,0 +1,6 @@
+package main
+
+const (
+	exitOk  = 0
+	exitErr = 1
+)
@@ -1,26 +1,33 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"flag"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/daystram/caroline/internal/config"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/event"
 	"github.com/daystram/caroline/internal/interaction"
+	"github.com/daystram/caroline/internal/logger"
 	"github.com/daystram/caroline/internal/repository"
 	"github.com/daystram/caroline/internal/server"
 	"github.com/daystram/caroline/internal/usecase"
 )
 
-func init() {
-	log.SetOutput(os.Stderr)
-}
+// log is replaced once the configuration is loaded.
+var log = logger.New(os.Stderr, logger.LevelInfo, logger.FormatLogfmt)
 
 func main() {
 	err := Main(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOk)
+	}
 	if err != nil {
-		log.Println("init:", err)
+		log.Error("init: failed", "err", err)
 		os.Exit(exitErr)
 	}
 
@@ -28,17 +35,31 @@ func main() {
 }
 
 func Main(args []string) error {
-	cfg, err := config.Load()
+	cfg, err := config.Load(args)
 	if err != nil {
 		return err
 	}
-	log.Println("init: version:", config.Version())
+	log = logger.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	log.Info("init: starting", "version", config.Version())
 
 	if cfg.DebugGuildID != "" {
-		log.Println("init: development mode: guildID:", cfg.DebugGuildID)
+		log.Info("init: development mode", "guild", cfg.DebugGuildID)
+	}
+	if !cfg.SpotifyEnabled() {
+		log.Warn("init: spotify credentials not specified, spotify links are disabled")
 	}
 
-	musicRepo, err := repository.NewMusicRepository(cfg.SpotifyClientID, cfg.SpotifyClientSecret)
+	musicRepo, err := repository.NewMusicRepository(cfg.SpotifyClientID, cfg.SpotifyClientSecret, cfg.YouTubeDLPPath, cfg.YouTubeDLPTimeout, log)
+	if err != nil {
+		return err
+	}
+	var voiceRepo domain.VoiceRepository
+	if len(cfg.AudioWorkers) > 0 {
+		log.Info("init: audio workers enabled", "workers", strings.Join(cfg.AudioWorkers, ","))
+		voiceRepo, err = repository.NewAudioWorkerRepository(cfg.AudioWorkers, log)
+	} else {
+		voiceRepo, err = repository.NewVoiceRepository()
+	}
 	if err != nil {
 		return err
 	}
@@ -46,31 +67,102 @@ func Main(args []string) error {
 	if err != nil {
 		return err
 	}
+	playlistRepo, err := repository.NewPlaylistRepository(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	musicChannelRepo, err := repository.NewMusicChannelRepository(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	settingsRepo, err := repository.NewSettingsRepository(cfg.DataDir, domain.GuildSettings{
+		Volume:          100,
+		DJRole:          cfg.DJRole,
+		IdleTimeout:     cfg.IdleTimeout,
+		DefaultLoop:     domain.LoopModeOff,
+		VoteSkipRatio:   cfg.VoteSkipRatio,
+		MaxUserTracks:   cfg.MaxUserTracks,
+		MaxUserDuration: cfg.MaxUserDuration,
+		DuplicatePolicy: cfg.DuplicatePolicy,
+		AutoPause:       cfg.AutoPause,
+		QueuePageSize:   domain.QueuePageSize,
+	})
+	if err != nil {
+		return err
+	}
+	webhookRepo, err := repository.NewWebhookRepository(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	scrobbleRepo, err := repository.NewScrobbleRepository(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	scrobbleClients := make(map[domain.ScrobbleService]domain.ScrobbleClient)
+	scrobbleClients[domain.ScrobbleServiceListenBrainz], err = repository.NewListenBrainzClient()
+	if err != nil {
+		return err
+	}
+	if cfg.LastFMEnabled() {
+		scrobbleClients[domain.ScrobbleServiceLastFM], err = repository.NewLastFMClient(cfg.LastFMAPIKey, cfg.LastFMAPISecret)
+		if err != nil {
+			return err
+		}
+	}
+
+	bus := event.NewBus(log)
 
 	musicUC, err := usecase.NewMusicUseCase(musicRepo)
 	if err != nil {
 		return err
 	}
-	playerUC, err := usecase.NewPlayerUseCase(musicRepo, queueRepo)
+	playerUC, err := usecase.NewPlayerUseCase(musicRepo, voiceRepo, queueRepo, musicChannelRepo, settingsRepo, bus, log)
+	if err != nil {
+		return err
+	}
+	queueUC, err := usecase.NewQueueUseCase(musicRepo, queueRepo, settingsRepo, bus)
+	if err != nil {
+		return err
+	}
+	playlistUC, err := usecase.NewPlaylistUseCase(playlistRepo)
+	if err != nil {
+		return err
+	}
+	permissionUC, err := usecase.NewPermissionUseCase(settingsRepo)
+	if err != nil {
+		return err
+	}
+	musicChannelUC, err := usecase.NewMusicChannelUseCase(musicChannelRepo)
+	if err != nil {
+		return err
+	}
+	settingsUC, err := usecase.NewSettingsUseCase(settingsRepo)
+	if err != nil {
+		return err
+	}
+	webhookUC, err := usecase.NewWebhookUseCase(webhookRepo, log)
 	if err != nil {
 		return err
 	}
-	queueUC, err := usecase.NewQueueUseCase(musicRepo, queueRepo)
+	scrobbleUC, err := usecase.NewScrobbleUseCase(scrobbleRepo, scrobbleClients, log)
 	if err != nil {
 		return err
 	}
 
-	srv, err := server.Start(cfg, musicUC, playerUC, queueUC)
+	srv, err := server.Start(cfg, musicUC, playerUC, queueUC, playlistUC, permissionUC, musicChannelUC, settingsUC, webhookUC, scrobbleUC, bus, log)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
-		log.Println("exit: server stopping")
-		_ = interaction.UnregisterAll(srv)
+		log.Info("exit: server stopping")
+		if srv.OwnsAllShards() {
+			// commands are global, leave them to the processes still running the other shards
+			_ = interaction.UnregisterAll(srv)
+		}
 		err := srv.Stop()
 		if err != nil {
-			log.Print("exit:", err)
+			log.Error("exit: failed", "err", err)
 			os.Exit(exitErr)
 		}
 	}()
@@ -80,7 +172,7 @@ func Main(args []string) error {
 		return err
 	}
 
-	log.Println("init: server started")
+	log.Info("init: server started")
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
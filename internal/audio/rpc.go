@@ -0,0 +1,39 @@
+// Package audio implements audio workers, which hold voice connections and play audio outside of the bot process.
+// The bot keeps the gateway session, and hands the voice session of each guild over to a worker through net/rpc
+// on a Unix socket.
+package audio
+
+import (
+	"time"
+)
+
+// ServiceName is the name the worker's RPC service is registered under.
+const ServiceName = "AudioWorker"
+
+// JoinArgs carries the voice session the bot obtained from the gateway, a later join for the same guild replaces it.
+type JoinArgs struct {
+	GuildID   string
+	UserID    string
+	SessionID string
+	Token     string
+	Endpoint  string
+}
+
+type PlayArgs struct {
+	GuildID string
+	Source  string
+	Offset  time.Duration
+	Volume  float64
+}
+
+type GuildArgs struct {
+	GuildID string
+}
+
+type StatsReply struct {
+	Connections int
+	// Ready lists the guilds whose voice connection is ready
+	Ready []string
+}
+
+type Empty struct{}
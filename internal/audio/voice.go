@@ -0,0 +1,375 @@
+package audio
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	voiceGatewayVersion = 4
+	// voiceEncryptionMode encrypts the payload with the RTP header as additional data,
+	// the nonce is a counter appended to the packet
+	voiceEncryptionMode = "aead_xchacha20_poly1305_rtpsize"
+	voiceConnectTimeout = 10 * time.Second
+	voiceWriteTimeout   = 5 * time.Second
+
+	udpDiscoveryLength = 74
+	udpKeepAlive       = 5 * time.Second
+	// silenceFrames are sent when playback ends to avoid interpolation with the next track
+	silenceFrames = 5
+)
+
+var silenceFrame = []byte{0xf8, 0xff, 0xfe}
+
+// voice gateway opcodes
+const (
+	voiceOpIdentify           = 0
+	voiceOpSelectProtocol     = 1
+	voiceOpReady              = 2
+	voiceOpHeartbeat          = 3
+	voiceOpSessionDescription = 4
+	voiceOpSpeaking           = 5
+	voiceOpHello              = 8
+)
+
+type voicePayload struct {
+	Op   int             `json:"op"`
+	Data json.RawMessage `json:"d"`
+}
+
+type voiceReady struct {
+	SSRC uint32 `json:"ssrc"`
+	IP   string `json:"ip"`
+	Port int    `json:"port"`
+}
+
+type voiceHello struct {
+	HeartbeatInterval float64 `json:"heartbeat_interval"`
+}
+
+type voiceSessionDescription struct {
+	SecretKey [32]byte `json:"secret_key"`
+}
+
+// voiceConn is a minimal voice connection, sending audio read from the guild's opus channel.
+// It is not reconnected, the bot rejoins through the gateway instead, which hands over a new session.
+type voiceConn struct {
+	args JoinArgs
+	log  *logger.Logger
+
+	ws     *websocket.Conn
+	wsLock sync.Mutex
+	udp    *net.UDPConn
+
+	ssrc      uint32
+	secretKey [32]byte
+
+	ready    bool
+	speaking bool
+	lock     sync.RWMutex
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// openVoiceConn completes the voice handshake and starts sending audio from opus.
+func openVoiceConn(args JoinArgs, opus <-chan []byte, log *logger.Logger) (*voiceConn, error) {
+	c := &voiceConn{
+		args:   args,
+		log:    log,
+		closed: make(chan struct{}),
+	}
+
+	endpoint := fmt.Sprintf("wss://%s/?v=%d", strings.TrimSuffix(args.Endpoint, ":80"), voiceGatewayVersion)
+	ws, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("voice: dial: %w", err)
+	}
+	c.ws = ws
+
+	err = c.handshake()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	c.ready = true
+	go c.listen()
+	go c.keepAlive()
+	go c.send(opus)
+
+	return c, nil
+}
+
+// handshake identifies, discovers the external UDP address and waits for the encryption key.
+func (c *voiceConn) handshake() error {
+	err := c.write(voiceOpIdentify, map[string]string{
+		"server_id":  c.args.GuildID,
+		"user_id":    c.args.UserID,
+		"session_id": c.args.SessionID,
+		"token":      c.args.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = c.ws.SetReadDeadline(time.Now().Add(voiceConnectTimeout))
+	defer func() {
+		_ = c.ws.SetReadDeadline(time.Time{})
+	}()
+	for {
+		var p voicePayload
+		err := c.ws.ReadJSON(&p)
+		if err != nil {
+			return fmt.Errorf("voice: handshake: %w", err)
+		}
+
+		switch p.Op {
+		case voiceOpHello:
+			var hello voiceHello
+			if err := json.Unmarshal(p.Data, &hello); err != nil {
+				return fmt.Errorf("voice: hello: %w", err)
+			}
+			if hello.HeartbeatInterval <= 0 {
+				return errors.New("voice: hello: missing heartbeat interval")
+			}
+			go c.heartbeat(time.Duration(hello.HeartbeatInterval * float64(time.Millisecond)))
+		case voiceOpReady:
+			var ready voiceReady
+			if err := json.Unmarshal(p.Data, &ready); err != nil {
+				return fmt.Errorf("voice: ready: %w", err)
+			}
+			c.ssrc = ready.SSRC
+			err := c.discover(ready)
+			if err != nil {
+				return err
+			}
+		case voiceOpSessionDescription:
+			var sd voiceSessionDescription
+			if err := json.Unmarshal(p.Data, &sd); err != nil {
+				return fmt.Errorf("voice: session description: %w", err)
+			}
+			c.secretKey = sd.SecretKey
+			return nil
+		}
+	}
+}
+
+// discover opens the UDP connection and selects the protocol with the address Discord sees it from.
+func (c *voiceConn) discover(ready voiceReady) error {
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(ready.IP, fmt.Sprint(ready.Port)))
+	if err != nil {
+		return fmt.Errorf("voice: udp: %w", err)
+	}
+	c.udp, err = net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return fmt.Errorf("voice: udp: %w", err)
+	}
+
+	req := make([]byte, udpDiscoveryLength)
+	binary.BigEndian.PutUint16(req, 1)
+	binary.BigEndian.PutUint16(req[2:], udpDiscoveryLength-4)
+	binary.BigEndian.PutUint32(req[4:], ready.SSRC)
+	_, err = c.udp.Write(req)
+	if err != nil {
+		return fmt.Errorf("voice: ip discovery: %w", err)
+	}
+
+	res := make([]byte, udpDiscoveryLength)
+	_ = c.udp.SetReadDeadline(time.Now().Add(voiceConnectTimeout))
+	n, err := c.udp.Read(res)
+	_ = c.udp.SetReadDeadline(time.Time{})
+	if err != nil {
+		return fmt.Errorf("voice: ip discovery: %w", err)
+	}
+	if n < udpDiscoveryLength {
+		return errors.New("voice: ip discovery: response too short")
+	}
+	ip := strings.TrimRight(string(res[8:udpDiscoveryLength-2]), "\x00")
+	port := binary.BigEndian.Uint16(res[udpDiscoveryLength-2:])
+
+	return c.write(voiceOpSelectProtocol, map[string]interface{}{
+		"protocol": "udp",
+		"data": map[string]interface{}{
+			"address": ip,
+			"port":    port,
+			"mode":    voiceEncryptionMode,
+		},
+	})
+}
+
+func (c *voiceConn) write(op int, data interface{}) error {
+	c.wsLock.Lock()
+	defer c.wsLock.Unlock()
+	_ = c.ws.SetWriteDeadline(time.Now().Add(voiceWriteTimeout))
+	return c.ws.WriteJSON(map[string]interface{}{"op": op, "d": data})
+}
+
+// listen drains the voice websocket, the connection is closed once it fails.
+func (c *voiceConn) listen() {
+	for {
+		_, _, err := c.ws.ReadMessage()
+		if err != nil {
+			select {
+			case <-c.closed:
+			default:
+				c.log.Warn("voice: connection lost", "err", err)
+				c.Close()
+			}
+			return
+		}
+	}
+}
+
+func (c *voiceConn) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := c.write(voiceOpHeartbeat, time.Now().UnixNano()/int64(time.Millisecond))
+			if err != nil {
+				c.log.Warn("voice: heartbeat failed", "err", err)
+				c.Close()
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *voiceConn) keepAlive() {
+	ticker := time.NewTicker(udpKeepAlive)
+	defer ticker.Stop()
+	packet := make([]byte, 8)
+	var sequence uint64
+	for {
+		select {
+		case <-ticker.C:
+			binary.LittleEndian.PutUint64(packet, sequence)
+			sequence++
+			_, _ = c.udp.Write(packet)
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// send paces opus frames into encrypted RTP packets, one frame every 20ms.
+func (c *voiceConn) send(opus <-chan []byte) {
+	defer func() {
+		c.lock.Lock()
+		c.ready = false
+		c.lock.Unlock()
+	}()
+
+	aead, err := chacha20poly1305.NewX(c.secretKey[:])
+	if err != nil {
+		c.log.Error("voice: invalid secret key", "err", err)
+		c.Close()
+		return
+	}
+
+	header := make([]byte, 12)
+	header[0] = 0x80
+	header[1] = 0x78
+	binary.BigEndian.PutUint32(header[8:], c.ssrc)
+	var (
+		sequence  uint16
+		timestamp uint32
+		counter   uint32
+		nonce     [chacha20poly1305.NonceSizeX]byte
+	)
+
+	ticker := time.NewTicker(time.Duration(util.AudioFrameSize) * time.Second / time.Duration(util.AudioFrameRate))
+	defer ticker.Stop()
+	for {
+		var frame []byte
+		select {
+		case frame = <-opus:
+		case <-c.closed:
+			return
+		}
+		if !c.isSpeaking() {
+			c.setSpeaking(true)
+		}
+
+		binary.BigEndian.PutUint16(header[2:], sequence)
+		binary.BigEndian.PutUint32(header[4:], timestamp)
+		binary.BigEndian.PutUint32(nonce[:], counter)
+		packet := aead.Seal(header[:12:12], nonce[:], frame, header)
+		packet = append(packet, nonce[:4]...)
+
+		select {
+		case <-ticker.C:
+		case <-c.closed:
+			return
+		}
+		_, err := c.udp.Write(packet)
+		if err != nil {
+			c.log.Warn("voice: send failed", "err", err)
+			c.Close()
+			return
+		}
+		sequence++
+		timestamp += util.AudioFrameSize
+		counter++
+	}
+}
+
+func (c *voiceConn) isSpeaking() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.speaking
+}
+
+func (c *voiceConn) setSpeaking(speaking bool) {
+	flag := 0
+	if speaking {
+		flag = 1
+	}
+	err := c.write(voiceOpSpeaking, map[string]interface{}{
+		"speaking": flag,
+		"delay":    0,
+		"ssrc":     c.ssrc,
+	})
+	if err != nil {
+		c.log.Warn("voice: failed to set speaking", "err", err)
+		return
+	}
+	c.lock.Lock()
+	c.speaking = speaking
+	c.lock.Unlock()
+}
+
+func (c *voiceConn) Ready() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.ready
+}
+
+func (c *voiceConn) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.wsLock.Lock()
+		_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(voiceWriteTimeout))
+		c.wsLock.Unlock()
+		_ = c.ws.Close()
+		if c.udp != nil {
+			_ = c.udp.Close()
+		}
+	})
+}
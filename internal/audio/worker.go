@@ -0,0 +1,265 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"layeh.com/gopus"
+
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/util"
+)
+
+// opusMaxBytes is the largest opus frame encoded from a single PCM frame.
+const opusMaxBytes = util.AudioFrameSize * util.AudioChannels * 2
+
+var errNotJoined = errors.New("not joined to a voice channel")
+
+// Worker holds the voice connections handed over by the bot, decoding and encoding audio into them.
+type Worker struct {
+	guilds map[string]*guildVoice
+	lock   sync.Mutex
+	log    *logger.Logger
+}
+
+// guildVoice outlives the guild's voice connections, so playback continues when the bot hands over a new session.
+type guildVoice struct {
+	conn *voiceConn
+	opus chan []byte
+	stop chan struct{}
+	left chan struct{}
+}
+
+func NewWorker(log *logger.Logger) *Worker {
+	return &Worker{
+		guilds: make(map[string]*guildVoice),
+		log:    log,
+	}
+}
+
+// Serve answers RPCs from bots connecting to the listener, blocking until it is closed.
+func (w *Worker) Serve(l net.Listener) error {
+	srv := rpc.NewServer()
+	err := srv.RegisterName(ServiceName, &workerService{w: w})
+	if err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go srv.ServeConn(conn)
+	}
+}
+
+// Close leaves every voice channel.
+func (w *Worker) Close() {
+	w.lock.Lock()
+	guildIDs := make([]string, 0, len(w.guilds))
+	for guildID := range w.guilds {
+		guildIDs = append(guildIDs, guildID)
+	}
+	w.lock.Unlock()
+
+	for _, guildID := range guildIDs {
+		w.leave(guildID)
+	}
+}
+
+func (w *Worker) join(args JoinArgs) error {
+	w.lock.Lock()
+	g, ok := w.guilds[args.GuildID]
+	if !ok {
+		g = &guildVoice{
+			opus: make(chan []byte, 2),
+			left: make(chan struct{}),
+		}
+		w.guilds[args.GuildID] = g
+	}
+	w.lock.Unlock()
+
+	conn, err := openVoiceConn(args, g.opus, w.log.With("guild", args.GuildID))
+	if err != nil {
+		w.log.Warn("join failed", "guild", args.GuildID, "err", err)
+		w.lock.Lock()
+		if w.guilds[args.GuildID] == g && g.conn == nil {
+			delete(w.guilds, args.GuildID)
+			close(g.left)
+		}
+		w.lock.Unlock()
+		return err
+	}
+
+	w.lock.Lock()
+	if w.guilds[args.GuildID] != g {
+		// left while connecting
+		w.lock.Unlock()
+		conn.Close()
+		return errNotJoined
+	}
+	prev := g.conn
+	g.conn = conn
+	w.lock.Unlock()
+
+	if prev != nil {
+		prev.Close()
+	}
+	w.log.Info("joined", "guild", args.GuildID)
+	return nil
+}
+
+func (w *Worker) leave(guildID string) {
+	w.lock.Lock()
+	g, ok := w.guilds[guildID]
+	if !ok {
+		w.lock.Unlock()
+		return
+	}
+	delete(w.guilds, guildID)
+	close(g.left)
+	w.lock.Unlock()
+
+	if g.conn != nil {
+		g.conn.Close()
+	}
+	w.log.Info("left", "guild", guildID)
+}
+
+// play blocks until the source ends, is stopped by the next play or stop, or the guild is left.
+func (w *Worker) play(args PlayArgs) error {
+	stop := make(chan struct{})
+	w.lock.Lock()
+	g, ok := w.guilds[args.GuildID]
+	if !ok {
+		w.lock.Unlock()
+		return errNotJoined
+	}
+	if g.stop != nil {
+		close(g.stop)
+	}
+	g.stop = stop
+	w.lock.Unlock()
+	defer func() {
+		w.lock.Lock()
+		if g.stop == stop {
+			g.stop = nil
+		}
+		w.lock.Unlock()
+	}()
+
+	// ffmpeg failing, or being killed, only ends this track
+	pcm, err := util.DecodeAudioFile(args.Source, args.Offset, args.Volume)
+	if err != nil {
+		w.log.Warn("decode failed", "guild", args.GuildID, "err", err)
+		return err
+	}
+	enc, err := gopus.NewEncoder(util.AudioFrameRate, util.AudioChannels, gopus.Audio)
+	if err != nil {
+		return err
+	}
+
+	send := func(frame []byte) bool {
+		select {
+		case g.opus <- frame:
+			return true
+		case <-stop:
+			return false
+		case <-g.left:
+			return false
+		}
+	}
+	for {
+		frame, err := util.ReadAudioFrame(pcm)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		opus, err := enc.Encode(frame, util.AudioFrameSize, opusMaxBytes)
+		if err != nil {
+			return err
+		}
+		if !send(opus) {
+			break
+		}
+	}
+
+	select {
+	case <-g.left:
+		return nil
+	default:
+	}
+	for i := 0; i < silenceFrames; i++ {
+		select {
+		case g.opus <- silenceFrame:
+		case <-g.left:
+			return nil
+		}
+	}
+	w.lock.Lock()
+	conn := g.conn
+	w.lock.Unlock()
+	if conn != nil && conn.Ready() {
+		conn.setSpeaking(false)
+	}
+	return nil
+}
+
+func (w *Worker) stop(guildID string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	g, ok := w.guilds[guildID]
+	if !ok || g.stop == nil {
+		return
+	}
+	close(g.stop)
+	g.stop = nil
+}
+
+func (w *Worker) stats() StatsReply {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	reply := StatsReply{Connections: len(w.guilds)}
+	for guildID, g := range w.guilds {
+		if g.conn != nil && g.conn.Ready() {
+			reply.Ready = append(reply.Ready, guildID)
+		}
+	}
+	return reply
+}
+
+// workerService exposes the worker over net/rpc, which requires every exported method to be an RPC.
+type workerService struct {
+	w *Worker
+}
+
+func (s *workerService) Join(args *JoinArgs, _ *Empty) error {
+	return s.w.join(*args)
+}
+
+func (s *workerService) Leave(args *GuildArgs, _ *Empty) error {
+	s.w.leave(args.GuildID)
+	return nil
+}
+
+func (s *workerService) Play(args *PlayArgs, _ *Empty) error {
+	return s.w.play(*args)
+}
+
+func (s *workerService) Stop(args *GuildArgs, _ *Empty) error {
+	s.w.stop(args.GuildID)
+	return nil
+}
+
+func (s *workerService) Stats(_ *Empty, reply *StatsReply) error {
+	*reply = s.w.stats()
+	return nil
+}
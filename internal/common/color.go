@@ -1,12 +1,13 @@
 package common
 
 const (
-	ColorBrand         = 0xfbea9a
-	ColorPlayerPlaying = 0x00d010
-	ColorPlayerLoading = 0x00800b
-	ColorPlayerStopped = 0xd01c00
-	ColorPlay          = 0x00d0d0
-	ColorQueue         = 0x0080ff
-	ColorAction        = 0xffb000
-	ColorError         = 0xd00050
+	ColorBrand          = 0xfbea9a
+	ColorPlayerPlaying  = 0x00d010
+	ColorPlayerLoading  = 0x00800b
+	ColorPlayerAutoplay = 0x8040d0
+	ColorPlayerStopped  = 0xd01c00
+	ColorPlay           = 0x00d0d0
+	ColorQueue          = 0x0080ff
+	ColorAction         = 0xffb000
+	ColorError          = 0xd00050
 )
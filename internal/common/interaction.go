@@ -8,7 +8,13 @@ const (
 	QueueComponentPreviousID = "queue_component:previous"
 	QueueComponentNextID     = "queue_component:next"
 
-	CommonComponentToggleQueueID   = "common_component:toggle_queue"
-	CommonComponentToggleLoopID    = "common_component:toggle_loop"
-	CommonComponentToggleShuffleID = "common_component:toggle_shuffle"
+	CommonComponentToggleQueueID    = "common_component:toggle_queue"
+	CommonComponentToggleLoopID     = "common_component:toggle_loop"
+	CommonComponentToggleShuffleID  = "common_component:toggle_shuffle"
+	CommonComponentToggleFairID     = "common_component:toggle_fair"
+	CommonComponentToggleAutoplayID = "common_component:toggle_autoplay"
+
+	VoteComponentSkipID = "vote_component:skip"
+
+	ScrobbleComponentLinkLastFMID = "scrobble_component:link_lastfm"
 )
@@ -27,6 +27,18 @@ var (
 		},
 	}
 
+	InteractionResponseNoPermission = &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: "Only DJs or the track requester can do that!",
+					Color:       ColorError,
+				},
+			},
+		},
+	}
+
 	InteractionResponseNotPlaying = &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -2,7 +2,17 @@ package config
 
 import (
 	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
 )
 
 type Config struct {
@@ -11,26 +21,312 @@ type Config struct {
 	SpotifyClientID     string
 	SpotifyClientSecret string
 
+	LastFMAPIKey    string
+	LastFMAPISecret string
+
+	YouTubeDLPPath    string
+	YouTubeDLPTimeout time.Duration
+
+	DataDir   string
+	LogLevel  logger.Level
+	LogFormat logger.Format
+
+	DJRole        string
+	VoteSkipRatio float64
+
+	MaxUserTracks   int
+	MaxUserDuration time.Duration
+	DuplicatePolicy domain.DuplicatePolicy
+
+	IdleTimeout time.Duration
+	AutoPause   bool
+
+	MessageContentIntent bool
+
+	ShardCount int
+	ShardID    int
+
+	AudioWorkers []string
+
+	HTTPAddr string
+	APIToken string
+
 	DebugGuildID string
 }
 
-func Load() (*Config, error) {
+func (c *Config) SpotifyEnabled() bool {
+	return c.SpotifyClientID != "" && c.SpotifyClientSecret != ""
+}
+
+func (c *Config) LastFMEnabled() bool {
+	return c.LastFMAPIKey != "" && c.LastFMAPISecret != ""
+}
+
+// option is a single setting, read from the config file, environment and flags.
+// The environment variable name is canonical, file keys and flag names are derived from it.
+type option struct {
+	env   string
+	def   string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+func (o option) key() string {
+	return strings.ToLower(o.env)
+}
+
+func (o option) flag() string {
+	return strings.ReplaceAll(o.key(), "_", "-")
+}
+
+var options = []option{
+	{"BOT_TOKEN", "", "Discord Bot token", func(c *Config, v string) error {
+		c.BotToken = v
+		return nil
+	}},
+	{"SP_CLIENT_ID", "", "Spotify client ID", func(c *Config, v string) error {
+		c.SpotifyClientID = v
+		return nil
+	}},
+	{"SP_CLIENT_SECRET", "", "Spotify client secret", func(c *Config, v string) error {
+		c.SpotifyClientSecret = v
+		return nil
+	}},
+	{"LASTFM_API_KEY", "", "Last.fm API key", func(c *Config, v string) error {
+		c.LastFMAPIKey = v
+		return nil
+	}},
+	{"LASTFM_API_SECRET", "", "Last.fm API shared secret", func(c *Config, v string) error {
+		c.LastFMAPISecret = v
+		return nil
+	}},
+	{"YT_DLP_PATH", "yt-dlp", "yt-dlp binary path", func(c *Config, v string) error {
+		c.YouTubeDLPPath = v
+		return nil
+	}},
+	{"YT_DLP_TIMEOUT", "5s", "yt-dlp call timeout", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return errors.New("must be a positive duration")
+		}
+		c.YouTubeDLPTimeout = d
+		return nil
+	}},
+	{"DATA_DIR", "data", "Persistent data path", func(c *Config, v string) error {
+		c.DataDir = v
+		return nil
+	}},
+	{"LOG_LEVEL", "info", "Log level (debug, info, warn, error)", func(c *Config, v string) error {
+		level, err := logger.ParseLevel(v)
+		if err != nil {
+			return errors.New("must be one of debug, info, warn or error")
+		}
+		c.LogLevel = level
+		return nil
+	}},
+	{"LOG_FORMAT", "logfmt", "Log format (logfmt, json)", func(c *Config, v string) error {
+		format, err := logger.ParseFormat(v)
+		if err != nil {
+			return errors.New("must be one of logfmt or json")
+		}
+		c.LogFormat = format
+		return nil
+	}},
+	{"DJ_ROLE", "DJ", "Default DJ role name or ID", func(c *Config, v string) error {
+		c.DJRole = v
+		return nil
+	}},
+	{"VOTE_SKIP_RATIO", "0.5", "Vote-skip listener ratio", func(c *Config, v string) error {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			return errors.New("must be a number between 0 and 1")
+		}
+		c.VoteSkipRatio = ratio
+		return nil
+	}},
+	{"MAX_USER_TRACKS", "0", "Max pending tracks per user", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errors.New("must be a non-negative integer")
+		}
+		c.MaxUserTracks = n
+		return nil
+	}},
+	{"MAX_USER_DURATION", "0", "Max pending duration per user", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return errors.New("must be a non-negative duration")
+		}
+		c.MaxUserDuration = d
+		return nil
+	}},
+	{"DUPLICATE_POLICY", "allow", "Default duplicate policy (allow, warn, reject)", func(c *Config, v string) error {
+		policy, err := domain.ParseDuplicatePolicy(v)
+		if err != nil {
+			return errors.New("must be one of allow, warn or reject")
+		}
+		c.DuplicatePolicy = policy
+		return nil
+	}},
+	{"IDLE_TIMEOUT", "5m", "Default idle disconnect timeout, 0 disables", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return errors.New("must be a non-negative duration")
+		}
+		c.IdleTimeout = d
+		return nil
+	}},
+	{"AUTO_PAUSE", "false", "Pause while voice channel is empty", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		c.AutoPause = b
+		return nil
+	}},
+	{"MESSAGE_CONTENT_INTENT", "false", "Read music channel queries, needs the privileged intent", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		c.MessageContentIntent = b
+		return nil
+	}},
+	{"SHARD_COUNT", "1", "Total number of shards, 0 uses the recommended count", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errors.New("must be a non-negative integer")
+		}
+		c.ShardCount = n
+		return nil
+	}},
+	{"SHARD_ID", "", "Shard run by this process, empty runs all shards", func(c *Config, v string) error {
+		if v == "" {
+			c.ShardID = -1
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errors.New("must be a non-negative integer")
+		}
+		c.ShardID = n
+		return nil
+	}},
+	{"AUDIO_WORKERS", "", "Comma-separated audio worker socket paths, empty plays audio in-process", func(c *Config, v string) error {
+		c.AudioWorkers = nil
+		for _, addr := range strings.Split(v, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				c.AudioWorkers = append(c.AudioWorkers, addr)
+			}
+		}
+		return nil
+	}},
+	{"HTTP_ADDR", "", "HTTP API, metrics and health check listen address, empty disables", func(c *Config, v string) error {
+		c.HTTPAddr = v
+		return nil
+	}},
+	{"API_TOKEN", "", "HTTP API bearer token, empty disables the API", func(c *Config, v string) error {
+		c.APIToken = v
+		return nil
+	}},
+	{"DEBUG_GUILD_ID", "", "Discord debug Guild ID", func(c *Config, v string) error {
+		c.DebugGuildID = v
+		return nil
+	}},
+}
+
+// Load reads the configuration, where later sources override earlier ones:
+// defaults, config file, environment variables, then command line flags.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("caroline", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML config file path")
+	flagValues := make(map[string]*string, len(options))
+	for _, o := range options {
+		flagValues[o.env] = fs.String(o.flag(), "", o.usage)
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(options))
+	for _, o := range options {
+		values[o.env] = o.def
+	}
+
+	if *configPath != "" {
+		fileValues, err := readFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range options {
+			if v, ok := fileValues[o.key()]; ok {
+				values[o.env] = v
+				delete(fileValues, o.key())
+			}
+		}
+		for k := range fileValues {
+			return nil, fmt.Errorf("config file: unknown option %s", k)
+		}
+	}
+
+	for _, o := range options {
+		if v, ok := os.LookupEnv(o.env); ok {
+			values[o.env] = v
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		for _, o := range options {
+			if o.flag() == f.Name {
+				values[o.env] = *flagValues[o.env]
+			}
+		}
+	})
+
 	c := &Config{}
+	for _, o := range options {
+		err := o.set(c, values[o.env])
+		if err != nil {
+			return nil, fmt.Errorf("%s %s", o.env, err)
+		}
+	}
 
-	var found bool
-	if c.BotToken, found = os.LookupEnv("BOT_TOKEN"); !found {
+	if c.BotToken == "" {
 		return nil, errors.New("BOT_TOKEN not specified")
 	}
+	if c.ShardID >= 0 && c.ShardID >= c.ShardCount {
+		// every process has to agree on the count, it cannot be left to Discord
+		return nil, errors.New("SHARD_ID must be less than SHARD_COUNT")
+	}
 
-	if c.SpotifyClientID, found = os.LookupEnv("SP_CLIENT_ID"); !found {
-		return nil, errors.New("SP_CLIENT_ID not specified")
+	return c, nil
+}
+
+// readFile reads a flat YAML mapping of lowercased option names to scalar values.
+func readFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
 
-	if c.SpotifyClientSecret, found = os.LookupEnv("SP_CLIENT_SECRET"); !found {
-		return nil, errors.New("SP_CLIENT_SECRET not specified")
+	raw := make(map[string]interface{})
+	err = yaml.Unmarshal(b, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
 	}
 
-	c.DebugGuildID, _ = os.LookupEnv("DEBUG_GUILD_ID")
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("config file: %s must be a scalar value", k)
+		case nil:
+			values[k] = ""
+		default:
+			values[k] = fmt.Sprint(v)
+		}
+	}
 
-	return c, nil
+	return values, nil
 }
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable Load reads, restoring them when the test ends.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	os.Unsetenv("CONFIG_FILE")
+	for _, o := range options {
+		t.Setenv(o.env, "")
+		os.Unsetenv(o.env)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		env         map[string]string
+		args        []string
+		wantErr     bool
+		wantToken   string
+		wantTimeout time.Duration
+		wantRatio   float64
+		wantDataDir string
+	}{
+		{
+			name:        "defaults",
+			env:         map[string]string{"BOT_TOKEN": "env"},
+			wantToken:   "env",
+			wantTimeout: 5 * time.Minute,
+			wantRatio:   0.5,
+			wantDataDir: "data",
+		},
+		{
+			name:        "file overrides defaults",
+			file:        "bot_token: file\nidle_timeout: 10m\nvote_skip_ratio: 0.75\n",
+			wantToken:   "file",
+			wantTimeout: 10 * time.Minute,
+			wantRatio:   0.75,
+			wantDataDir: "data",
+		},
+		{
+			name:        "env overrides file",
+			file:        "bot_token: file\nidle_timeout: 10m\n",
+			env:         map[string]string{"BOT_TOKEN": "env", "IDLE_TIMEOUT": "1m"},
+			wantToken:   "env",
+			wantTimeout: time.Minute,
+			wantRatio:   0.5,
+			wantDataDir: "data",
+		},
+		{
+			name:        "flags override env",
+			file:        "bot_token: file\n",
+			env:         map[string]string{"BOT_TOKEN": "env", "IDLE_TIMEOUT": "1m"},
+			args:        []string{"-bot-token", "flag", "-idle-timeout", "0", "-vote-skip-ratio", "1"},
+			wantToken:   "flag",
+			wantTimeout: 0,
+			wantRatio:   1,
+			wantDataDir: "data",
+		},
+		{
+			name:        "shards share the data directory",
+			env:         map[string]string{"BOT_TOKEN": "env", "DATA_DIR": "/var/lib/caroline", "SHARD_COUNT": "4", "SHARD_ID": "2"},
+			wantToken:   "env",
+			wantTimeout: 5 * time.Minute,
+			wantRatio:   0.5,
+			wantDataDir: "/var/lib/caroline",
+		},
+		{
+			name:    "missing bot token",
+			file:    "idle_timeout: 10m\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown file option",
+			file:    "bot_token: file\nspeed: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "nested file value",
+			file:    "bot_token:\n  value: file\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			env:     map[string]string{"BOT_TOKEN": "env", "VOTE_SKIP_RATIO": "2"},
+			wantErr: true,
+		},
+		{
+			name:    "shard outside count",
+			env:     map[string]string{"BOT_TOKEN": "env", "SHARD_COUNT": "2", "SHARD_ID": "2"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.args
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				args = append([]string{"-config", path}, args...)
+			}
+
+			c, err := Load(args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if c.BotToken != tt.wantToken {
+				t.Errorf("BotToken = %q, want %q", c.BotToken, tt.wantToken)
+			}
+			if c.IdleTimeout != tt.wantTimeout {
+				t.Errorf("IdleTimeout = %s, want %s", c.IdleTimeout, tt.wantTimeout)
+			}
+			if c.VoteSkipRatio != tt.wantRatio {
+				t.Errorf("VoteSkipRatio = %g, want %g", c.VoteSkipRatio, tt.wantRatio)
+			}
+			if c.DataDir != tt.wantDataDir {
+				t.Errorf("DataDir = %q, want %q", c.DataDir, tt.wantDataDir)
+			}
+		})
+	}
+}
@@ -5,10 +5,25 @@ import (
 )
 
 var (
-	ErrBadFormat        = errors.New("bad format")
-	ErrInOtherChannel   = errors.New("bot is in a different voice channel")
-	ErrMusicNotFound    = errors.New("music not found")
-	ErrNotPlaying       = errors.New("not playing in any voice channels")
-	ErrQueueNotFound    = errors.New("queue not found")
-	ErrQueueOutOfBounds = errors.New("queue out of bounds")
+	ErrBadFormat            = errors.New("bad format")
+	ErrDuplicate            = errors.New("already in queue")
+	ErrInOtherChannel       = errors.New("bot is in a different voice channel")
+	ErrMusicNotFound        = errors.New("music not found")
+	ErrMusicChannelNotFound = errors.New("music channel not found")
+	ErrNotPlaying           = errors.New("not playing in any voice channels")
+	ErrPlaylistEmpty        = errors.New("playlist empty")
+	ErrPlaylistNotFound     = errors.New("playlist not found")
+	ErrQueueFull            = errors.New("queue is full")
+	ErrQueueNotFound        = errors.New("queue not found")
+	ErrQueueOutOfBounds     = errors.New("queue out of bounds")
+	ErrScrobbleDisabled     = errors.New("scrobbling service is not configured")
+	ErrScrobbleNotFound     = errors.New("scrobbling account not linked")
+	ErrScrobbleUnauthorized = errors.New("scrobbling account not authorized")
+	ErrSettingNotFound      = errors.New("setting not found")
+	ErrSpotifyDisabled      = errors.New("spotify is not configured")
+	ErrStageModerator       = errors.New("stage moderator permission required")
+	ErrUserTrackLimit       = errors.New("per-user track limit reached")
+	ErrUserTimeLimit        = errors.New("per-user duration limit reached")
+	ErrWebhookLimit         = errors.New("webhook limit reached")
+	ErrWebhookNotFound      = errors.New("webhook not found")
 )
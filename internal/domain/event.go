@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+)
+
+// Event is a change to a guild's player or queue. Subscribers type switch on the concrete events below.
+type Event interface {
+	EventGuildID() string
+}
+
+// TrackLoading is emitted when the player moves on to a track, before it is loaded and streamed.
+type TrackLoading struct {
+	GuildID string
+	Music   *Music
+}
+
+// TrackStarted is emitted once audio starts streaming. Resumed is set when it continues the same play
+// from Offset, after a pause or a reconnect.
+type TrackStarted struct {
+	GuildID        string
+	VoiceChannelID string
+	Music          *Music
+	Offset         time.Duration
+	Resumed        bool
+}
+
+// TrackEnded is emitted when a track stops streaming, Elapsed being the position it stopped at and Listened
+// the time it streamed across pauses and reconnects. Paused is set when the play is to be resumed later.
+type TrackEnded struct {
+	GuildID        string
+	VoiceChannelID string
+	Music          *Music
+	Elapsed        time.Duration
+	Listened       time.Duration
+	Skipped        bool
+	Paused         bool
+}
+
+// QueueChanged is emitted when tracks are added, removed or reordered, or a queue mode changes.
+type QueueChanged struct {
+	GuildID string
+}
+
+type LoopChanged struct {
+	GuildID string
+	Mode    LoopMode
+}
+
+// PlayerStateChanged is emitted when the player's status or voice connection changes.
+type PlayerStateChanged struct {
+	GuildID        string
+	VoiceChannelID string
+	Status         PlayerStatus
+}
+
+func (e TrackLoading) EventGuildID() string       { return e.GuildID }
+func (e TrackStarted) EventGuildID() string       { return e.GuildID }
+func (e TrackEnded) EventGuildID() string         { return e.GuildID }
+func (e QueueChanged) EventGuildID() string       { return e.GuildID }
+func (e LoopChanged) EventGuildID() string        { return e.GuildID }
+func (e PlayerStateChanged) EventGuildID() string { return e.GuildID }
+
+type EventBus interface {
+	// Publish never blocks, each subscriber receives events in order on its own goroutine.
+	Publish(e Event)
+	// Subscribe registers a handler and returns a function to unsubscribe it.
+	Subscribe(name string, handler func(e Event)) func()
+}
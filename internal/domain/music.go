@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -23,6 +24,24 @@ type Music struct {
 	URL       string
 	Thumbnail string
 	Duration  time.Duration
+
+	// Artist and Track are best effort, both are empty when unknown.
+	Artist string
+	Track  string
+}
+
+// SameAs reports whether both musics point to the same track.
+func (m *Music) SameAs(o *Music) bool {
+	switch {
+	case m.YouTubeVideoID != "" && m.YouTubeVideoID == o.YouTubeVideoID:
+		return true
+	case m.SpotifyTrackID != "" && m.SpotifyTrackID == o.SpotifyTrackID:
+		return true
+	case m.Loaded && o.Loaded && m.URL != "" && m.URL == o.URL:
+		return true
+	default:
+		return false
+	}
 }
 
 type MusicSource uint
@@ -32,6 +51,7 @@ const (
 	MusicSourceSpotifyTrack
 	MusicSourceYouTubeVideo
 	MusicSourceSearch
+	MusicSourceAutoplay
 )
 
 func (s MusicSource) String() string {
@@ -44,17 +64,70 @@ func (s MusicSource) String() string {
 		return "YouTube"
 	case MusicSourceSearch:
 		return "Search"
+	case MusicSourceAutoplay:
+		return "Autoplay"
 	default:
 		return "invalid source"
 	}
 }
 
+type MusicFormat uint
+
+const (
+	MusicFormatJSON MusicFormat = iota
+	MusicFormatM3U
+	MusicFormatXSPF
+)
+
+func (f MusicFormat) String() string {
+	switch f {
+	case MusicFormatJSON:
+		return "json"
+	case MusicFormatM3U:
+		return "m3u"
+	case MusicFormatXSPF:
+		return "xspf"
+	default:
+		return "invalid format"
+	}
+}
+
+func (f MusicFormat) ContentType() string {
+	switch f {
+	case MusicFormatJSON:
+		return "application/json"
+	case MusicFormatM3U:
+		return "audio/x-mpegurl"
+	case MusicFormatXSPF:
+		return "application/xspf+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func ParseMusicFormat(s string) (MusicFormat, error) {
+	switch strings.TrimPrefix(strings.ToLower(s), ".") {
+	case "json":
+		return MusicFormatJSON, nil
+	case "m3u", "m3u8":
+		return MusicFormatM3U, nil
+	case "xspf":
+		return MusicFormatXSPF, nil
+	default:
+		return 0, ErrBadFormat
+	}
+}
+
 type MusicUseCase interface {
 	Parse(query string, user *discordgo.User) (string, []*Music, error)
+	Export(musics []*Music, format MusicFormat) ([]byte, error)
+	Import(data []byte, format MusicFormat, user *discordgo.User) ([]*Music, error)
 }
 
 type MusicRepository interface {
+	SpotifyEnabled() bool
 	GetSpotifyPlaylist(id string) (*spotify.FullPlaylist, []spotify.PlaylistTrack, error)
+	GetRelated(seeds []*Music, limit int) ([]*Music, error)
 	Load(music *Music) error
 	GetStreamURL(music *Music) (string, error)
 }
@@ -0,0 +1,20 @@
+package domain
+
+type MusicChannel struct {
+	GuildID   string
+	ChannelID string
+	MessageID string
+	Queries   bool
+}
+
+type MusicChannelUseCase interface {
+	Get(guildID string) (*MusicChannel, error)
+	Set(guildID, channelID string, queries bool) (*MusicChannel, error)
+	Unset(guildID string) (*MusicChannel, error)
+}
+
+type MusicChannelRepository interface {
+	Get(guildID string) (*MusicChannel, error)
+	Save(mc *MusicChannel) error
+	Delete(guildID string) error
+}
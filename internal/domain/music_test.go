@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestMusicSameAs(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Music
+		want bool
+	}{
+		{
+			name: "same youtube video",
+			a:    Music{YouTubeVideoID: "yt1", Query: "a"},
+			b:    Music{YouTubeVideoID: "yt1", Query: "b"},
+			want: true,
+		},
+		{
+			name: "different youtube videos",
+			a:    Music{YouTubeVideoID: "yt1"},
+			b:    Music{YouTubeVideoID: "yt2"},
+			want: false,
+		},
+		{
+			name: "same spotify track",
+			a:    Music{SpotifyTrackID: "sp1"},
+			b:    Music{SpotifyTrackID: "sp1"},
+			want: true,
+		},
+		{
+			name: "searches resolving to the same url",
+			a:    Music{Query: "song", Loaded: true, URL: "https://example.com/1"},
+			b:    Music{Query: "the song", Loaded: true, URL: "https://example.com/1"},
+			want: true,
+		},
+		{
+			name: "unloaded search",
+			a:    Music{Query: "song", URL: "https://example.com/1"},
+			b:    Music{Query: "song", Loaded: true, URL: "https://example.com/1"},
+			want: false,
+		},
+		{
+			name: "same query alone",
+			a:    Music{Query: "song"},
+			b:    Music{Query: "song"},
+			want: false,
+		},
+		{
+			name: "both without ids",
+			a:    Music{Loaded: true},
+			b:    Music{Loaded: true},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.SameAs(&tt.b); got != tt.want {
+				t.Errorf("SameAs() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.SameAs(&tt.a); got != tt.want {
+				t.Errorf("SameAs() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
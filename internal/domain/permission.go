@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+type PermissionAction uint
+
+const (
+	PermissionActionSkip PermissionAction = iota
+	PermissionActionJump
+	PermissionActionRemove
+	PermissionActionMove
+	PermissionActionReset
+	PermissionActionKick
+	PermissionActionControl
+	PermissionActionSummon
+	PermissionActionPrevious
+)
+
+func (a PermissionAction) String() string {
+	switch a {
+	case PermissionActionSkip:
+		return "skip"
+	case PermissionActionJump:
+		return "jump"
+	case PermissionActionRemove:
+		return "remove"
+	case PermissionActionMove:
+		return "move"
+	case PermissionActionReset:
+		return "reset"
+	case PermissionActionKick:
+		return "kick"
+	case PermissionActionControl:
+		return "control"
+	case PermissionActionSummon:
+		return "summon"
+	case PermissionActionPrevious:
+		return "previous"
+	default:
+		return "invalid action"
+	}
+}
+
+type PermissionResult uint
+
+const (
+	PermissionGranted PermissionResult = iota
+	PermissionDenied
+	PermissionVoteRequired
+)
+
+type PermissionUseCase interface {
+	IsDJ(s *discordgo.Session, guildID string, member *discordgo.Member) bool
+	Check(s *discordgo.Session, p *Player, q *Queue, member *discordgo.Member, action PermissionAction, target *Music) PermissionResult
+	VoteSkip(s *discordgo.Session, p *Player, q *Queue, member *discordgo.Member) (int, int, bool, error)
+}
@@ -14,13 +14,28 @@ const (
 	PlayerStatusUninitialized
 )
 
+func (s PlayerStatus) String() string {
+	switch s {
+	case PlayerStatusPlaying:
+		return "playing"
+	case PlayerStatusStopped:
+		return "stopped"
+	case PlayerStatusUninitialized:
+		return "uninitialized"
+	default:
+		return "invalid status"
+	}
+}
+
 type Player struct {
 	GuildID          string
+	ShardID          int
 	VoiceChannel     *discordgo.Channel
 	NPChannel        *discordgo.Channel
-	Conn             *discordgo.VoiceConnection
+	Conn             VoiceConnection
 	Status           PlayerStatus
 	CurrentStartTime time.Time
+	ReconnectAttempt int
 
 	LastNPMessageID string
 	ShowQueue       bool
@@ -33,15 +48,22 @@ const (
 	PlayerActionSkip
 	PlayerActionStop
 	PlayerActionKick
+	// PlayerActionPause stops like PlayerActionStop, but the next play resumes from the same position
+	PlayerActionPause
 )
 
 type PlayerUseCase interface {
+	Partition(shardCount int)
 	Create(s *discordgo.Session, vch, sch *discordgo.Channel, q *Queue) (*Player, error)
 	Get(guildID string) (*Player, error)
 	GetAll() []*Player
 	Play(p *Player) error
 	Skip(p *Player) error
 	Stop(p *Player) error
+	MoveVoiceChannel(p *Player, vch *discordgo.Channel) error
+	MoveNPChannel(s *discordgo.Session, p *Player, q *Queue, npch *discordgo.Channel) error
+	UpdateVoiceState(s *discordgo.Session, p *Player, q *Queue, vs *discordgo.VoiceState) error
+	UpdateIdle(s *discordgo.Session, p *Player, q *Queue) error
 	UpdateNPMessage(s *discordgo.Session, p *Player, q *Queue, queuePage int, toggleQueue, keepLast bool) error
 	Kick(s *discordgo.Session, p *Player, q *Queue) error
 	Count() int
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	PlaylistMaxNameLength = 32
+)
+
+type Playlist struct {
+	GuildID           string
+	Name              string
+	CreatedAt         time.Time
+	CreatedByID       string
+	CreatedByUsername string
+	Tracks            []*Music
+}
+
+func (p *Playlist) Duration() time.Duration {
+	var d time.Duration
+	for _, m := range p.Tracks {
+		d += m.Duration
+	}
+	return d
+}
+
+type PlaylistUseCase interface {
+	Save(q *Queue, name string, user *discordgo.User) (*Playlist, error)
+	Get(guildID, name string) (*Playlist, error)
+	List(guildID string) ([]*Playlist, error)
+	Delete(guildID, name string) error
+	Tracks(pl *Playlist, user *discordgo.User) []*Music
+}
+
+type PlaylistRepository interface {
+	Save(playlist *Playlist) error
+	Get(guildID, name string) (*Playlist, error)
+	List(guildID string) ([]*Playlist, error)
+	Delete(guildID, name string) error
+}
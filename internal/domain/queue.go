@@ -1,7 +1,7 @@
 package domain
 
 const (
-	QueuePageSize = 10
+	QueuePageSize = 10 // default, overridden per guild by the queue-page-size setting
 )
 
 type LoopMode uint
@@ -25,6 +25,19 @@ func (m LoopMode) String() string {
 	}
 }
 
+func ParseLoopMode(s string) (LoopMode, error) {
+	switch s {
+	case "off":
+		return LoopModeOff, nil
+	case "one":
+		return LoopModeOne, nil
+	case "all":
+		return LoopModeAll, nil
+	default:
+		return 0, ErrBadFormat
+	}
+}
+
 type ShuffleMode uint
 
 const (
@@ -43,17 +56,93 @@ func (m ShuffleMode) String() string {
 	}
 }
 
+type FairMode uint
+
+const (
+	FairModeOff FairMode = iota
+	FairModeOn
+)
+
+func (m FairMode) String() string {
+	switch m {
+	case FairModeOff:
+		return "off"
+	case FairModeOn:
+		return "on"
+	default:
+		return "invalid mode"
+	}
+}
+
+type AutoplayMode uint
+
+const (
+	AutoplayModeOff AutoplayMode = iota
+	AutoplayModeOn
+)
+
+func (m AutoplayMode) String() string {
+	switch m {
+	case AutoplayModeOff:
+		return "off"
+	case AutoplayModeOn:
+		return "on"
+	default:
+		return "invalid mode"
+	}
+}
+
+type DuplicatePolicy uint
+
+const (
+	DuplicatePolicyAllow DuplicatePolicy = iota
+	DuplicatePolicyWarn
+	DuplicatePolicyReject
+)
+
+func (p DuplicatePolicy) String() string {
+	switch p {
+	case DuplicatePolicyAllow:
+		return "allow"
+	case DuplicatePolicyWarn:
+		return "warn"
+	case DuplicatePolicyReject:
+		return "reject"
+	default:
+		return "invalid policy"
+	}
+}
+
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch s {
+	case "allow":
+		return DuplicatePolicyAllow, nil
+	case "warn":
+		return DuplicatePolicyWarn, nil
+	case "reject":
+		return DuplicatePolicyReject, nil
+	default:
+		return 0, ErrBadFormat
+	}
+}
+
 type Queue struct {
 	GuildID      string
 	ActiveTracks []*Music
 	CurrentPos   int
 	Loop         LoopMode
+	// EndPos follows the last track once the queue has ended, the tracks before it were played and are not
+	// upcoming until playback resumes. It is zero otherwise.
+	EndPos int
 
 	LastQueueMessageID string
 	LastPage           int
 
 	Shuffle        ShuffleMode
 	OriginalTracks []*Music
+
+	Fair     FairMode
+	Autoplay AutoplayMode
 }
 
 func (q *Queue) NowPlaying() *Music {
@@ -69,6 +158,7 @@ func (q *Queue) Proceed() *Music {
 		if q.CurrentPos == len(q.ActiveTracks)-1 {
 			// end of queue
 			q.CurrentPos = -1
+			q.EndPos = len(q.ActiveTracks)
 		} else {
 			q.CurrentPos++
 		}
@@ -89,16 +179,38 @@ func (q *Queue) IsEmpty() bool {
 	return len(q.ActiveTracks) == 0
 }
 
-func (q *Queue) GetPageItems(page int) ([]*Music, int, error) {
+// Upcoming returns the tracks yet to be played, leaving out the played ones once the queue has ended.
+func (q *Queue) Upcoming() []*Music {
+	pos := q.UpcomingPos()
+	if pos > len(q.ActiveTracks) {
+		return nil
+	}
+	return q.ActiveTracks[pos:]
+}
+
+// UpcomingPos returns the position of the first upcoming track.
+func (q *Queue) UpcomingPos() int {
+	if q.EndPos > q.CurrentPos+1 {
+		return q.EndPos
+	}
+	return q.CurrentPos + 1
+}
+
+// Resume counts every track as upcoming again once playback resumes after the queue has ended.
+func (q *Queue) Resume() {
+	q.EndPos = 0
+}
+
+func (q *Queue) GetPageItems(page, pageSize int) ([]*Music, int, error) {
 	if page == -1 {
-		page = q.CurrentPos / QueuePageSize
+		page = q.CurrentPos / pageSize
 	}
-	if page < 0 || page > (len(q.ActiveTracks)-1)/QueuePageSize {
+	if page < 0 || page > (len(q.ActiveTracks)-1)/pageSize {
 		return nil, -1, ErrQueueOutOfBounds
 	}
 
-	start := page * QueuePageSize
-	end := (page + 1) * QueuePageSize
+	start := page * pageSize
+	end := (page + 1) * pageSize
 	if limit := len(q.ActiveTracks); end > limit {
 		end = limit
 	}
@@ -108,14 +220,34 @@ func (q *Queue) GetPageItems(page int) ([]*Music, int, error) {
 	return items, page, nil
 }
 
+// EnqueueResult describes the musics queued at once by EnqueueAll.
+type EnqueueResult struct {
+	// Positions of the queued musics, resolved once every music was inserted
+	Positions  []int
+	StartPos   int
+	EndPos     int
+	Duplicates int
+	// DuplicatePolicy is whether the duplicates were queued with a warning or skipped
+	DuplicatePolicy DuplicatePolicy
+	Rejected        int
+	// RejectErr is why the last rejected music was not queued
+	RejectErr error
+}
+
 type QueueUseCase interface {
 	Get(guildID string) (*Queue, error)
-	Enqueue(q *Queue, music *Music, pos int) (int, error)
+	// Enqueue returns the track number, and whether the music was queued despite being a duplicate.
+	Enqueue(q *Queue, music *Music, pos int) (int, bool, error)
+	// EnqueueAll counts rejected and duplicate musics instead of failing on them, unexpected errors are
+	// returned along with the musics queued regardless.
+	EnqueueAll(q *Queue, musics []*Music, pos int) (*EnqueueResult, error)
 	Jump(q *Queue, pos int) error
 	Move(q *Queue, from, to int) error
 	Remove(q *Queue, pos int) error
 	SetLoopMode(q *Queue, mode LoopMode) error
 	SetShuffleMode(q *Queue, mode ShuffleMode) error
+	SetFairMode(q *Queue, mode FairMode) error
+	SetAutoplayMode(q *Queue, mode AutoplayMode) error
 	Clear(q *Queue) error
 }
 
@@ -128,5 +260,7 @@ type QueueRepository interface {
 	Remove(guildID string, pos int) error
 	SetLoopMode(guildID string, mode LoopMode) error
 	SetShuffleMode(guildID string, mode ShuffleMode) error
+	SetFairMode(guildID string, mode FairMode) error
+	SetAutoplayMode(guildID string, mode AutoplayMode) error
 	Clear(guildID string) error
 }
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+)
+
+type ScrobbleService string
+
+const (
+	ScrobbleServiceLastFM       ScrobbleService = "lastfm"
+	ScrobbleServiceListenBrainz ScrobbleService = "listenbrainz"
+)
+
+var ScrobbleServices = []ScrobbleService{
+	ScrobbleServiceLastFM,
+	ScrobbleServiceListenBrainz,
+}
+
+func (s ScrobbleService) String() string {
+	switch s {
+	case ScrobbleServiceLastFM:
+		return "Last.fm"
+	case ScrobbleServiceListenBrainz:
+		return "ListenBrainz"
+	default:
+		return "invalid service"
+	}
+}
+
+// ScrobbleAccount is a user's linked account, Key being the Last.fm session key or ListenBrainz user token.
+type ScrobbleAccount struct {
+	UserID   string
+	Service  ScrobbleService
+	Username string
+	Key      string
+	LinkedAt time.Time
+}
+
+type Scrobble struct {
+	Artist    string
+	Track     string
+	Duration  time.Duration
+	URL       string
+	StartedAt time.Time
+}
+
+type ScrobbleUseCase interface {
+	Enabled(service ScrobbleService) bool
+	// Authorize starts linking an account through the service's website, returning the URL the user has to visit.
+	Authorize(userID string, service ScrobbleService) (string, error)
+	// Link links an account, token is the user token for services not linked through their website.
+	Link(userID string, service ScrobbleService, token string) (*ScrobbleAccount, error)
+	Unlink(userID string, service ScrobbleService) (*ScrobbleAccount, error)
+	List(userID string) ([]*ScrobbleAccount, error)
+	// NowPlaying and Scrobble submit to the linked accounts of userIDs in the background.
+	NowPlaying(userIDs []string, music *Music)
+	Scrobble(userIDs []string, music *Music, startedAt time.Time, listened time.Duration)
+}
+
+type ScrobbleRepository interface {
+	Save(account *ScrobbleAccount) error
+	List(userID string) ([]*ScrobbleAccount, error)
+	Delete(userID string, service ScrobbleService) error
+}
+
+// ScrobbleClient talks to a scrobbling service.
+type ScrobbleClient interface {
+	// AuthToken requests a token for the user to authorize at the returned URL.
+	AuthToken() (token, url string, err error)
+	// Session exchanges an authorized or user token for the account's username and key.
+	Session(token string) (username, key string, err error)
+	NowPlaying(key string, s *Scrobble) error
+	Scrobble(key string, s *Scrobble) error
+}
@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SettingKey string
+
+const (
+	SettingVolume          SettingKey = "volume"
+	SettingDJRole          SettingKey = "dj-role"
+	SettingAnnounceChannel SettingKey = "announce-channel"
+	SettingMaxQueueLength  SettingKey = "max-queue-length"
+	SettingIdleTimeout     SettingKey = "idle-timeout"
+	SettingDefaultLoop     SettingKey = "default-loop"
+	SettingStageInstance   SettingKey = "stage-instance"
+	SettingVoteSkipRatio   SettingKey = "vote-skip-ratio"
+	SettingMaxUserTracks   SettingKey = "max-user-tracks"
+	SettingMaxUserDuration SettingKey = "max-user-duration"
+	SettingDuplicatePolicy SettingKey = "duplicate-policy"
+	SettingAutoPause       SettingKey = "auto-pause"
+	SettingQueuePageSize   SettingKey = "queue-page-size"
+	SettingColor           SettingKey = "color"
+)
+
+var SettingKeys = []SettingKey{
+	SettingVolume,
+	SettingDJRole,
+	SettingAnnounceChannel,
+	SettingMaxQueueLength,
+	SettingIdleTimeout,
+	SettingDefaultLoop,
+	SettingStageInstance,
+	SettingVoteSkipRatio,
+	SettingMaxUserTracks,
+	SettingMaxUserDuration,
+	SettingDuplicatePolicy,
+	SettingAutoPause,
+	SettingQueuePageSize,
+	SettingColor,
+}
+
+const (
+	SettingsMinVolume = 1
+	SettingsMaxVolume = 200
+
+	SettingsMinQueuePageSize = 1
+	SettingsMaxQueuePageSize = 25
+)
+
+type GuildSettings struct {
+	GuildID           string
+	Volume            int
+	DJRole            string
+	AnnounceChannelID string
+	MaxQueueLength    int
+	IdleTimeout       time.Duration
+	DefaultLoop       LoopMode
+	StageInstance     bool
+	VoteSkipRatio     float64
+	MaxUserTracks     int
+	MaxUserDuration   time.Duration
+	DuplicatePolicy   DuplicatePolicy
+	AutoPause         bool
+	QueuePageSize     int
+	Color             int // accent color of queue and settings messages, 0 uses the default
+}
+
+// Apply parses value and sets it on the setting identified by key.
+func (g *GuildSettings) Apply(key SettingKey, value string) error {
+	value = strings.TrimSpace(value)
+	switch key {
+	case SettingVolume:
+		v, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || v < SettingsMinVolume || v > SettingsMaxVolume {
+			return ErrBadFormat
+		}
+		g.Volume = v
+	case SettingDJRole:
+		if value == "none" {
+			value = ""
+		}
+		g.DJRole = strings.TrimSuffix(strings.TrimPrefix(value, "<@&"), ">")
+	case SettingAnnounceChannel:
+		if value == "none" {
+			value = ""
+		}
+		g.AnnounceChannelID = strings.TrimSuffix(strings.TrimPrefix(value, "<#"), ">")
+	case SettingMaxQueueLength:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return ErrBadFormat
+		}
+		g.MaxQueueLength = n
+	case SettingIdleTimeout:
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return ErrBadFormat
+		}
+		g.IdleTimeout = d
+	case SettingDefaultLoop:
+		mode, err := ParseLoopMode(value)
+		if err != nil {
+			return err
+		}
+		g.DefaultLoop = mode
+	case SettingStageInstance:
+		b, err := parseToggle(value)
+		if err != nil {
+			return err
+		}
+		g.StageInstance = b
+	case SettingVoteSkipRatio:
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			return ErrBadFormat
+		}
+		g.VoteSkipRatio = ratio
+	case SettingMaxUserTracks:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return ErrBadFormat
+		}
+		g.MaxUserTracks = n
+	case SettingMaxUserDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return ErrBadFormat
+		}
+		g.MaxUserDuration = d
+	case SettingDuplicatePolicy:
+		policy, err := ParseDuplicatePolicy(value)
+		if err != nil {
+			return err
+		}
+		g.DuplicatePolicy = policy
+	case SettingAutoPause:
+		b, err := parseToggle(value)
+		if err != nil {
+			return err
+		}
+		g.AutoPause = b
+	case SettingQueuePageSize:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < SettingsMinQueuePageSize || n > SettingsMaxQueuePageSize {
+			return ErrBadFormat
+		}
+		g.QueuePageSize = n
+	case SettingColor:
+		if value == "default" {
+			g.Color = 0
+			return nil
+		}
+		c, err := strconv.ParseUint(strings.TrimPrefix(value, "#"), 16, 24)
+		if err != nil {
+			return ErrBadFormat
+		}
+		g.Color = int(c)
+	default:
+		return ErrSettingNotFound
+	}
+	return nil
+}
+
+// Value formats the setting identified by key for display.
+func (g *GuildSettings) Value(key SettingKey) string {
+	switch key {
+	case SettingVolume:
+		return fmt.Sprintf("%d%%", g.Volume)
+	case SettingDJRole:
+		if g.DJRole == "" {
+			return "none"
+		}
+		return g.DJRole
+	case SettingAnnounceChannel:
+		if g.AnnounceChannelID == "" {
+			return "none"
+		}
+		return fmt.Sprintf("<#%s>", g.AnnounceChannelID)
+	case SettingMaxQueueLength:
+		if g.MaxQueueLength == 0 {
+			return "unlimited"
+		}
+		return strconv.Itoa(g.MaxQueueLength)
+	case SettingIdleTimeout:
+		if g.IdleTimeout == 0 {
+			return "disabled"
+		}
+		return g.IdleTimeout.String()
+	case SettingDefaultLoop:
+		return g.DefaultLoop.String()
+	case SettingStageInstance:
+		return formatToggle(g.StageInstance)
+	case SettingVoteSkipRatio:
+		return strconv.FormatFloat(g.VoteSkipRatio, 'g', -1, 64)
+	case SettingMaxUserTracks:
+		if g.MaxUserTracks == 0 {
+			return "unlimited"
+		}
+		return strconv.Itoa(g.MaxUserTracks)
+	case SettingMaxUserDuration:
+		if g.MaxUserDuration == 0 {
+			return "unlimited"
+		}
+		return g.MaxUserDuration.String()
+	case SettingDuplicatePolicy:
+		return g.DuplicatePolicy.String()
+	case SettingAutoPause:
+		return formatToggle(g.AutoPause)
+	case SettingQueuePageSize:
+		return strconv.Itoa(g.QueuePageSize)
+	case SettingColor:
+		if g.Color == 0 {
+			return "default"
+		}
+		return fmt.Sprintf("#%06x", g.Color)
+	default:
+		return ""
+	}
+}
+
+// AccentColor returns the configured color, or def when unset.
+func (g *GuildSettings) AccentColor(def int) int {
+	if g.Color == 0 {
+		return def
+	}
+	return g.Color
+}
+
+func parseToggle(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "true":
+		return true, nil
+	case "off", "false":
+		return false, nil
+	default:
+		return false, ErrBadFormat
+	}
+}
+
+func formatToggle(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+type SettingsUseCase interface {
+	Get(guildID string) (*GuildSettings, error)
+	Set(guildID string, key SettingKey, value string) (*GuildSettings, error)
+	Reset(guildID string, key SettingKey) (*GuildSettings, error)
+	ResetAll(guildID string) (*GuildSettings, error)
+}
+
+// SettingsRepository stores per-guild overrides on top of the global defaults.
+type SettingsRepository interface {
+	Get(guildID string) (*GuildSettings, error)
+	Set(guildID string, key SettingKey, value string) error
+	Reset(guildID string, key SettingKey) error
+	ResetAll(guildID string) error
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGuildSettingsApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     SettingKey
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "volume", key: SettingVolume, value: "80", want: "80%"},
+		{name: "volume with percent", key: SettingVolume, value: " 150% ", want: "150%"},
+		{name: "volume too low", key: SettingVolume, value: "0", wantErr: ErrBadFormat},
+		{name: "volume too high", key: SettingVolume, value: "201", wantErr: ErrBadFormat},
+		{name: "volume not a number", key: SettingVolume, value: "loud", wantErr: ErrBadFormat},
+		{name: "dj role mention", key: SettingDJRole, value: "<@&123>", want: "123"},
+		{name: "dj role none", key: SettingDJRole, value: "none", want: "none"},
+		{name: "announce channel mention", key: SettingAnnounceChannel, value: "<#456>", want: "<#456>"},
+		{name: "announce channel id", key: SettingAnnounceChannel, value: "456", want: "<#456>"},
+		{name: "announce channel none", key: SettingAnnounceChannel, value: "none", want: "none"},
+		{name: "max queue length", key: SettingMaxQueueLength, value: "50", want: "50"},
+		{name: "max queue length unlimited", key: SettingMaxQueueLength, value: "0", want: "unlimited"},
+		{name: "max queue length negative", key: SettingMaxQueueLength, value: "-1", wantErr: ErrBadFormat},
+		{name: "idle timeout", key: SettingIdleTimeout, value: "5m", want: "5m0s"},
+		{name: "idle timeout disabled", key: SettingIdleTimeout, value: "0s", want: "disabled"},
+		{name: "idle timeout negative", key: SettingIdleTimeout, value: "-1m", wantErr: ErrBadFormat},
+		{name: "idle timeout without unit", key: SettingIdleTimeout, value: "5", wantErr: ErrBadFormat},
+		{name: "default loop", key: SettingDefaultLoop, value: "all", want: "all"},
+		{name: "default loop invalid", key: SettingDefaultLoop, value: "twice", wantErr: ErrBadFormat},
+		{name: "stage instance on", key: SettingStageInstance, value: "on", want: "on"},
+		{name: "stage instance true", key: SettingStageInstance, value: "TRUE", want: "on"},
+		{name: "stage instance false", key: SettingStageInstance, value: "false", want: "off"},
+		{name: "stage instance invalid", key: SettingStageInstance, value: "yes", wantErr: ErrBadFormat},
+		{name: "vote skip ratio", key: SettingVoteSkipRatio, value: "0.5", want: "0.5"},
+		{name: "vote skip ratio all", key: SettingVoteSkipRatio, value: "1", want: "1"},
+		{name: "vote skip ratio zero", key: SettingVoteSkipRatio, value: "0", wantErr: ErrBadFormat},
+		{name: "vote skip ratio above one", key: SettingVoteSkipRatio, value: "1.5", wantErr: ErrBadFormat},
+		{name: "max user tracks", key: SettingMaxUserTracks, value: "3", want: "3"},
+		{name: "max user tracks unlimited", key: SettingMaxUserTracks, value: "0", want: "unlimited"},
+		{name: "max user tracks negative", key: SettingMaxUserTracks, value: "-3", wantErr: ErrBadFormat},
+		{name: "max user duration", key: SettingMaxUserDuration, value: "30m", want: "30m0s"},
+		{name: "max user duration unlimited", key: SettingMaxUserDuration, value: "0", want: "unlimited"},
+		{name: "max user duration invalid", key: SettingMaxUserDuration, value: "long", wantErr: ErrBadFormat},
+		{name: "duplicate policy", key: SettingDuplicatePolicy, value: "reject", want: "reject"},
+		{name: "duplicate policy invalid", key: SettingDuplicatePolicy, value: "ignore", wantErr: ErrBadFormat},
+		{name: "auto pause off", key: SettingAutoPause, value: "off", want: "off"},
+		{name: "queue page size", key: SettingQueuePageSize, value: "25", want: "25"},
+		{name: "queue page size too small", key: SettingQueuePageSize, value: "0", wantErr: ErrBadFormat},
+		{name: "queue page size too large", key: SettingQueuePageSize, value: "26", wantErr: ErrBadFormat},
+		{name: "color", key: SettingColor, value: "#ff8800", want: "#ff8800"},
+		{name: "color without hash", key: SettingColor, value: "00ff00", want: "#00ff00"},
+		{name: "color default", key: SettingColor, value: "default", want: "default"},
+		{name: "color too wide", key: SettingColor, value: "#1000000", wantErr: ErrBadFormat},
+		{name: "color not hex", key: SettingColor, value: "orange", wantErr: ErrBadFormat},
+		{name: "unknown key", key: SettingKey("speed"), value: "1", wantErr: ErrSettingNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &GuildSettings{Volume: 100, QueuePageSize: 10, Color: 0x123456}
+			err := g.Apply(tt.key, tt.value)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got := g.Value(tt.key); got != tt.want {
+				t.Errorf("Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// VoiceConnection streams audio to a joined voice channel.
+type VoiceConnection interface {
+	// Ready reports whether audio can currently be sent.
+	Ready() bool
+	// Play streams a file or stream URL from the offset, blocking until it ends, stop is signalled or the connection drops.
+	Play(source string, offset time.Duration, volume float64, stop <-chan bool)
+	ChangeChannel(channelID string) error
+	Disconnect() error
+}
+
+// VoiceRepository joins voice channels, with audio played either in the bot process or by audio workers.
+type VoiceRepository interface {
+	Join(s *discordgo.Session, guildID, channelID string) (VoiceConnection, error)
+}
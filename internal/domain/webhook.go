@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type Webhook struct {
+	ID          string
+	GuildID     string
+	URL         string
+	Secret      string
+	CreatedAt   time.Time
+	CreatedByID string
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventTrackStarted WebhookEvent = "track.started"
+	WebhookEventTrackEnded   WebhookEvent = "track.ended"
+	WebhookEventTrackSkipped WebhookEvent = "track.skipped"
+)
+
+// WebhookDelivery is a track event sent to every webhook registered in the guild.
+// Position is the offset playback started at, or the position it ended at.
+type WebhookDelivery struct {
+	Event     WebhookEvent
+	GuildID   string
+	GuildName string
+	Music     *Music
+	Position  time.Duration
+	Time      time.Time
+}
+
+type WebhookUseCase interface {
+	Add(guildID, url string, user *discordgo.User) (*Webhook, error)
+	List(guildID string) ([]*Webhook, error)
+	Remove(guildID, id string) (*Webhook, error)
+	// Deliver sends d in the background, retrying failed deliveries.
+	Deliver(d *WebhookDelivery)
+}
+
+type WebhookRepository interface {
+	Save(webhook *Webhook) error
+	List(guildID string) ([]*Webhook, error)
+	Delete(guildID, id string) error
+}
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
+)
+
+const subscriberBufferSize = 256
+
+func NewBus(log *logger.Logger) domain.EventBus {
+	return &bus{
+		log:         log.With("component", "events"),
+		subscribers: make(map[*subscriber]bool),
+	}
+}
+
+type bus struct {
+	log *logger.Logger
+
+	subscribers map[*subscriber]bool
+	lock        sync.RWMutex
+}
+
+type subscriber struct {
+	name   string
+	events chan domain.Event
+}
+
+var _ domain.EventBus = (*bus)(nil)
+
+func (b *bus) Publish(e domain.Event) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- e:
+		default:
+			// publishers may hold player locks, never wait on slow subscribers
+			b.log.Warn("event dropped", "event", Name(e), "guild", e.EventGuildID(), "subscriber", sub.name)
+		}
+	}
+}
+
+func (b *bus) Subscribe(name string, handler func(e domain.Event)) func() {
+	sub := &subscriber{
+		name:   name,
+		events: make(chan domain.Event, subscriberBufferSize),
+	}
+	go func() {
+		for e := range sub.events {
+			handler(e)
+		}
+	}()
+
+	b.lock.Lock()
+	b.subscribers[sub] = true
+	b.lock.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.lock.Lock()
+			delete(b.subscribers, sub)
+			b.lock.Unlock()
+			close(sub.events)
+		})
+	}
+}
+
+// Name returns the event type name, e.g. TrackStarted.
+func Name(e domain.Event) string {
+	name := fmt.Sprintf("%T", e)
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -2,7 +2,6 @@ package caroline
 
 import (
 	"errors"
-	"log"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -36,19 +35,19 @@ func byeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interact
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -56,16 +55,20 @@ func byeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interact
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionKick, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		// kick player and clear queue
 		err = srv.UC.Player.Kick(s, p, q)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		err = srv.UC.Queue.Clear(q)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -81,7 +84,7 @@ func byeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interact
 			},
 		})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -0,0 +1,84 @@
+package caroline
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const duplicateCommandName = "duplicates"
+
+func RegisterDuplicate(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        duplicateCommandName,
+		Description: "Set duplicate track policy",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "policy",
+				Description: "What to do when a track is already in queue",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Allow", Value: domain.DuplicatePolicyAllow.String()},
+					{Name: "Warn", Value: domain.DuplicatePolicyWarn.String()},
+					{Name: "Reject", Value: domain.DuplicatePolicyReject.String()},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[duplicateCommandName] = duplicateCommand(srv)
+
+	return nil
+}
+
+func duplicateCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		if !srv.UC.Permission.IsDJ(s, i.GuildID, i.Member) {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Only DJs can change the duplicate policy!"))
+			return
+		}
+
+		// parse policy
+		policyRaw, ok := i.ApplicationCommandData().Options[0].Value.(string)
+		if !ok {
+			srv.Log(i).Error("option type mismatch")
+			return
+		}
+		policy, err := domain.ParseDuplicatePolicy(policyRaw)
+		if err != nil {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Invalid policy!"))
+			return
+		}
+
+		// shorthand for the duplicate-policy setting, so that it is kept across queues
+		_, err = srv.UC.Settings.Set(i.GuildID, domain.SettingDuplicatePolicy, policy.String())
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Description: fmt.Sprintf("Duplicate policy set to **%s**!", policy),
+						Color:       common.ColorAction,
+					},
+				},
+			},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
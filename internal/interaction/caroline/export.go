@@ -0,0 +1,102 @@
+package caroline
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const exportCommandName = "export"
+
+func RegisterExport(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        exportCommandName,
+		Description: "Export queue as a file",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "format",
+				Description: "File format",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "JSON", Value: domain.MusicFormatJSON.String()},
+					{Name: "M3U", Value: domain.MusicFormatM3U.String()},
+					{Name: "XSPF", Value: domain.MusicFormatXSPF.String()},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[exportCommandName] = exportCommand(srv)
+
+	return nil
+}
+
+func exportCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// get queue
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		if q.IsEmpty() {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Queue is empty, nothing to export!"))
+			return
+		}
+
+		// parse format
+		format := domain.MusicFormatJSON
+		if len(i.ApplicationCommandData().Options) > 0 {
+			formatRaw, ok := i.ApplicationCommandData().Options[0].Value.(string)
+			if !ok {
+				srv.Log(i).Error("option type mismatch")
+				return
+			}
+			format, err = domain.ParseMusicFormat(formatRaw)
+			if err != nil {
+				_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Unsupported format!"))
+				return
+			}
+		}
+
+		// export
+		b, err := srv.UC.Music.Export(q.ActiveTracks, format)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Description: fmt.Sprintf("Exported **%d** %s!", len(q.ActiveTracks), util.Plural("track", len(q.ActiveTracks))),
+						Color:       common.ColorAction,
+					},
+				},
+				Files: []*discordgo.File{
+					{
+						Name:        fmt.Sprintf("queue.%s", format),
+						ContentType: format.ContentType(),
+						Reader:      bytes.NewReader(b),
+					},
+				},
+			},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package caroline
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	importCommandName = "import"
+
+	importMaxFileSize = 1 << 20 // 1 MiB
+)
+
+func RegisterImport(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        importCommandName,
+		Description: "Import queue from a JSON, M3U or XSPF file",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "file",
+				Description: "Exported queue file",
+				Required:    true,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[importCommandName] = importCommand(srv)
+
+	return nil
+}
+
+func importCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// check if user in voice channel
+		vs, err := util.GetUserVS(s, i, true, "You have to be in a voice channel to play something!")
+		if errors.Is(err, discordgo.ErrStateNotFound) {
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// get player and queue
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		p, err := srv.UC.Player.Get(i.GuildID)
+		if errors.Is(err, domain.ErrNotPlaying) {
+			vch, err := s.Channel(vs.ChannelID)
+			if err != nil {
+				srv.Log(i).Error("interaction failed", "err", err)
+				return
+			}
+			npch, err := s.Channel(i.ChannelID)
+			if err != nil {
+				srv.Log(i).Error("interaction failed", "err", err)
+				return
+			}
+			p, err = srv.UC.Player.Create(s, vch, npch, q)
+			if err != nil {
+				srv.Log(i).Error("interaction failed", "err", err)
+				return
+			}
+		} else if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		if !util.IsSameVC(p, vs) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
+			return
+		}
+
+		// resolve attachment and format
+		attachmentID, ok := i.ApplicationCommandData().Options[0].Value.(string)
+		if !ok {
+			srv.Log(i).Error("option type mismatch")
+			return
+		}
+		attachment, ok := i.ApplicationCommandData().Resolved.Attachments[attachmentID]
+		if !ok {
+			srv.Log(i).Error("attachment not resolved")
+			return
+		}
+		format, err := domain.ParseMusicFormat(filepath.Ext(attachment.Filename))
+		if err != nil {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Unsupported file, use a `.json`, `.m3u` or `.xspf` file!"))
+			return
+		}
+
+		// initial response
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Description: "Importing to queue...",
+						Color:       common.ColorAction,
+					},
+				},
+			},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+
+		// download and parse musics
+		b, err := util.DownloadAttachment(attachment, importMaxFileSize)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{{Description: "Failed downloading file!", Color: common.ColorError}},
+			})
+			return
+		}
+		musics, err := srv.UC.Music.Import(b, format, i.Member.User)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{{Description: "No valid tracks found in file!", Color: common.ColorError}},
+			})
+			return
+		}
+
+		// enqueue
+		res := enqueueMusics(srv, q, musics, -1)
+		if res.err != nil {
+			srv.Log(i).Error("interaction failed", "err", res.err)
+		}
+		if len(res.Positions) == 0 {
+			_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{res.rejectionEmbed()},
+			})
+			if err != nil {
+				srv.Log(i).Error("interaction failed", "err", err)
+			}
+			return
+		}
+		startPos, endPos := res.StartPos, res.EndPos
+
+		resp := &discordgo.MessageEmbed{
+			Title:       "Added to Queue",
+			Description: fmt.Sprintf("**%d** items from **%s** imported!", len(res.Positions), attachment.Filename),
+			Color:       common.ColorPlay,
+			Author: &discordgo.MessageEmbedAuthor{
+				Name:    i.Member.User.Username,
+				IconURL: discordgo.EndpointUserAvatar(i.Member.User.ID, i.Member.User.Avatar),
+			},
+			Fields: []*discordgo.MessageEmbedField{
+				{
+					Name:   "Position",
+					Value:  fmt.Sprintf("%d to %d of %d", startPos+1, endPos, len(q.ActiveTracks)),
+					Inline: true,
+				},
+			},
+		}
+		if res.Duplicates > 0 {
+			resp.Fields = append(resp.Fields, res.duplicatesField())
+		}
+		if res.Rejected > 0 {
+			resp.Fields = append(resp.Fields, res.rejectionField())
+		}
+		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Embeds: &[]*discordgo.MessageEmbed{resp},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+
+		if p.Status != domain.PlayerStatusPlaying {
+			// immediately play the first imported track when player is not playing
+			err = srv.UC.Queue.Jump(q, startPos)
+			if err != nil {
+				srv.Log(i).Error("interaction failed", "err", err)
+				return
+			}
+		}
+		err = srv.UC.Player.Play(p)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package caroline
 import (
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -45,19 +44,19 @@ func jumpCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -69,11 +68,15 @@ func jumpCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionJump, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		// parse pos
 		posRaw, ok := i.ApplicationCommandData().Options[0].Value.(string)
 		if !ok {
-			log.Printf("%s: %s: option type mismatch\n", i.Type, util.InteractionName(i))
+			srv.Log(i).Error("option type mismatch")
 			return
 		}
 		pos, err := util.ParseRelativePosOption(q, posRaw)
@@ -85,12 +88,12 @@ func jumpCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 		// jump queue
 		err = srv.UC.Queue.Jump(q, pos)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		err = srv.UC.Player.Skip(p)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -106,7 +109,7 @@ func jumpCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			},
 		})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -3,7 +3,6 @@ package caroline
 import (
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -51,19 +50,19 @@ func moveCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -79,12 +78,12 @@ func moveCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 		// parse indices
 		f, ok := i.ApplicationCommandData().Options[0].Value.(float64)
 		if !ok {
-			log.Printf("%s: %s: option type mismatch\n", i.Type, util.InteractionName(i))
+			srv.Log(i).Error("option type mismatch")
 			return
 		}
 		t, ok := i.ApplicationCommandData().Options[1].Value.(float64)
 		if !ok {
-			log.Printf("%s: %s: option type mismatch\n", i.Type, util.InteractionName(i))
+			srv.Log(i).Error("option type mismatch")
 			return
 		}
 		from, to := int(f)-1, int(t)-1
@@ -93,6 +92,10 @@ func moveCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseInvalidPosition)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionMove, q.ActiveTracks[from]) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		if from == q.CurrentPos || to == q.CurrentPos {
 			_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -112,7 +115,7 @@ func moveCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 		// move
 		err = srv.UC.Queue.Move(q, from, to)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -128,13 +131,7 @@ func moveCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			},
 		})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
-		}
-
-		err = srv.UC.Player.UpdateNPMessage(s, p, q, -1, false, true)
-		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
-			return
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -0,0 +1,305 @@
+package caroline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	musicChannelCommandName = "music-channel"
+
+	musicChannelSubcommandSet   = "set"
+	musicChannelSubcommandUnset = "unset"
+
+	musicChannelTransientTTL = 5 * time.Second
+)
+
+func RegisterMusicChannel(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        musicChannelCommandName,
+		Description: "Manage dedicated music channel",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        musicChannelSubcommandSet,
+				Description: "Keep a pinned player message in a channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Music channel",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+						Required:     true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "queries",
+						Description: "Treat messages in the channel as play queries",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        musicChannelSubcommandUnset,
+				Description: "Stop using a dedicated music channel",
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[musicChannelCommandName] = musicChannelCommand(srv)
+	srv.AddHandler(musicChannelMessage(srv))
+
+	return nil
+}
+
+func musicChannelCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		if !srv.UC.Permission.IsDJ(s, i.GuildID, i.Member) {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Only DJs can change the music channel!"))
+			return
+		}
+
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case musicChannelSubcommandSet:
+			musicChannelSet(srv, s, i, sub)
+		case musicChannelSubcommandUnset:
+			musicChannelUnset(srv, s, i)
+		default:
+			srv.Log(i).Error("unknown subcommand", "subcommand", sub.Name)
+		}
+	}
+}
+
+func musicChannelSet(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	ch := sub.Options[0].ChannelValue(s)
+	if ch == nil || ch.GuildID != i.GuildID || ch.Type != discordgo.ChannelTypeGuildText {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Invalid text channel!"))
+		return
+	}
+	// queries are read from message content, which needs the privileged intent
+	queries := srv.MessageContentIntent
+	if len(sub.Options) > 1 {
+		queries = sub.Options[1].BoolValue()
+	}
+	if queries && !srv.MessageContentIntent {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Queries are not supported, I cannot read messages without the message content intent!"))
+		return
+	}
+
+	// unpin the previous player message when moving channels
+	prev, err := srv.UC.MusicChannel.Get(i.GuildID)
+	if err == nil && prev.ChannelID != ch.ID && prev.MessageID != "" {
+		_ = s.ChannelMessageUnpin(prev.ChannelID, prev.MessageID)
+	}
+
+	_, err = srv.UC.MusicChannel.Set(i.GuildID, ch.ID, queries)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	description := fmt.Sprintf("Player message will be kept in <#%s>!", ch.ID)
+	if queries {
+		description = fmt.Sprintf("Player message will be kept in <#%s>, send a message there to play something!", ch.ID)
+	}
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: description,
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+
+	// move the player message right away when playing
+	p, err := srv.UC.Player.Get(i.GuildID)
+	if err != nil || !util.IsPlayerReady(p) {
+		return
+	}
+	q, err := srv.UC.Queue.Get(i.GuildID)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+	err = srv.UC.Player.UpdateNPMessage(s, p, q, -1, false, true)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func musicChannelUnset(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	mc, err := srv.UC.MusicChannel.Unset(i.GuildID)
+	if errors.Is(err, domain.ErrMusicChannelNotFound) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("No music channel is set!"))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+	if mc.MessageID != "" {
+		_ = s.ChannelMessageUnpin(mc.ChannelID, mc.MessageID)
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: "Music channel unset!",
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+// musicChannelMessage treats plain messages in the music channel as /p queries.
+func musicChannelMessage(srv *server.Server) func(*discordgo.Session, *discordgo.MessageCreate) {
+	return func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.GuildID == "" || m.Author == nil || m.Author.Bot {
+			return
+		}
+		mc, err := srv.UC.MusicChannel.Get(m.GuildID)
+		if err != nil || mc.ChannelID != m.ChannelID || !mc.Queries || !srv.MessageContentIntent {
+			return
+		}
+		query := strings.TrimSpace(m.Content)
+		if query == "" {
+			// nothing to play, e.g. attachments only, which are left alone
+			return
+		}
+
+		// keep the channel clean, only the player message stays
+		defer func() {
+			_ = s.ChannelMessageDelete(m.ChannelID, m.ID)
+		}()
+		mlog := srv.Logger.With(
+			"request_id", m.ID,
+			"guild", m.GuildID,
+			"user", m.Author.ID,
+			"type", "message",
+			"interaction", musicChannelCommandName,
+		)
+		mlog.Info("message received")
+
+		// check if user in voice channel
+		vs, err := s.State.VoiceState(m.GuildID, m.Author.ID)
+		if err != nil {
+			sendTransient(s, m.ChannelID, &discordgo.MessageEmbed{
+				Description: "You have to be in a voice channel to play something!",
+				Color:       common.ColorError,
+			})
+			return
+		}
+
+		// get player and queue
+		q, err := srv.UC.Queue.Get(m.GuildID)
+		if err != nil {
+			mlog.Error("message failed", "err", err)
+			return
+		}
+		p, err := srv.UC.Player.Get(m.GuildID)
+		if errors.Is(err, domain.ErrNotPlaying) {
+			vch, err := s.Channel(vs.ChannelID)
+			if err != nil {
+				mlog.Error("message failed", "err", err)
+				return
+			}
+			npch, err := s.Channel(m.ChannelID)
+			if err != nil {
+				mlog.Error("message failed", "err", err)
+				return
+			}
+			p, err = srv.UC.Player.Create(s, vch, npch, q)
+			if err != nil {
+				mlog.Error("message failed", "err", err)
+				return
+			}
+		} else if err != nil {
+			mlog.Error("message failed", "err", err)
+			return
+		}
+
+		if !util.IsSameVC(p, vs) {
+			sendTransient(s, m.ChannelID, common.InteractionResponseDifferentVC.Data.Embeds[0])
+			return
+		}
+
+		// parse and enqueue musics
+		_, musics, err := srv.UC.Music.Parse(query, m.Author)
+		if errors.Is(err, domain.ErrSpotifyDisabled) {
+			sendTransient(s, m.ChannelID, &discordgo.MessageEmbed{
+				Description: "Spotify links are not supported!",
+				Color:       common.ColorError,
+			})
+			return
+		}
+		if err != nil {
+			mlog.Error("message failed", "err", err)
+			sendTransient(s, m.ChannelID, &discordgo.MessageEmbed{
+				Description: fmt.Sprintf("Could not find `%s`!", query),
+				Color:       common.ColorError,
+			})
+			return
+		}
+		res := enqueueMusics(srv, q, musics, -1)
+		if res.err != nil {
+			mlog.Error("message failed", "err", res.err)
+		}
+		if len(res.Positions) == 0 {
+			sendTransient(s, m.ChannelID, res.rejectionEmbed())
+			return
+		}
+		sendTransient(s, m.ChannelID, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("**%d** %s added to queue by <@%s>!", len(res.Positions), util.Plural("track", len(res.Positions)), m.Author.ID),
+			Color:       common.ColorPlay,
+		})
+
+		if p.Status != domain.PlayerStatusPlaying {
+			err = srv.UC.Queue.Jump(q, res.StartPos)
+			if err != nil {
+				mlog.Error("message failed", "err", err)
+				return
+			}
+		}
+		err = srv.UC.Player.Play(p)
+		if err != nil {
+			mlog.Error("message failed", "err", err)
+		}
+	}
+}
+
+// sendTransient sends an embed that removes itself shortly after.
+func sendTransient(s *discordgo.Session, channelID string, emb *discordgo.MessageEmbed) {
+	msg, err := s.ChannelMessageSendEmbed(channelID, emb)
+	if err != nil {
+		return
+	}
+	time.AfterFunc(musicChannelTransientTTL, func() {
+		_ = s.ChannelMessageDelete(channelID, msg.ID)
+	})
+}
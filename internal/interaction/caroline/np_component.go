@@ -2,7 +2,6 @@ package caroline
 
 import (
 	"errors"
-	"log"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -19,6 +18,8 @@ func RegisterNPComponent(srv *server.Server, interactionHandlers map[string]func
 	interactionHandlers[common.CommonComponentToggleQueueID] = commonComponentToggleQueue(srv)
 	interactionHandlers[common.CommonComponentToggleLoopID] = commonComponentToggleLoop(srv)
 	interactionHandlers[common.CommonComponentToggleShuffleID] = commonComponentToggleShuffle(srv)
+	interactionHandlers[common.CommonComponentToggleFairID] = commonComponentToggleFair(srv)
+	interactionHandlers[common.CommonComponentToggleAutoplayID] = commonComponentToggleAutoplay(srv)
 	return nil
 }
 
@@ -30,19 +31,19 @@ func npComponentPrevious(srv *server.Server) func(*discordgo.Session, *discordgo
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -54,22 +55,26 @@ func npComponentPrevious(srv *server.Server) func(*discordgo.Session, *discordgo
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionPrevious, q.NowPlaying()) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		// jump queue
 		err = srv.UC.Queue.Jump(q, (q.CurrentPos+len(q.ActiveTracks)-1)%len(q.ActiveTracks))
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		err = srv.UC.Player.Skip(p)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -82,19 +87,19 @@ func npComponentNext(srv *server.Server) func(*discordgo.Session, *discordgo.Int
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -106,22 +111,25 @@ func npComponentNext(srv *server.Server) func(*discordgo.Session, *discordgo.Int
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
 			return
 		}
-
-		// jump queue
-		err = srv.UC.Queue.Jump(q, (q.CurrentPos+1)%len(q.ActiveTracks))
-		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+		switch srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionSkip, q.NowPlaying()) {
+		case domain.PermissionDenied:
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		case domain.PermissionVoteRequired:
+			voteSkip(srv, s, i, p, q, discordgo.InteractionResponseChannelMessageWithSource)
 			return
 		}
-		err = srv.UC.Player.Skip(p)
+
+		// skip track
+		err = skipTrack(srv, p, q)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -134,19 +142,19 @@ func npComponentTogglePlay(srv *server.Server) func(*discordgo.Session, *discord
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -158,26 +166,30 @@ func npComponentTogglePlay(srv *server.Server) func(*discordgo.Session, *discord
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionControl, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		// toggle play
 		switch p.Status {
 		case domain.PlayerStatusStopped:
 			err = srv.UC.Player.Play(p)
 			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+				srv.Log(i).Error("interaction failed", "err", err)
 				return
 			}
 		case domain.PlayerStatusPlaying:
 			err = srv.UC.Player.Stop(p)
 			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+				srv.Log(i).Error("interaction failed", "err", err)
 				return
 			}
 		}
 
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -187,12 +199,12 @@ func commonComponentToggleQueue(srv *server.Server) func(*discordgo.Session, *di
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -204,13 +216,13 @@ func commonComponentToggleQueue(srv *server.Server) func(*discordgo.Session, *di
 		// update queue message
 		err = srv.UC.Player.UpdateNPMessage(s, p, q, -1, true, true)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -223,19 +235,19 @@ func commonComponentToggleLoop(srv *server.Server) func(*discordgo.Session, *dis
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -247,6 +259,10 @@ func commonComponentToggleLoop(srv *server.Server) func(*discordgo.Session, *dis
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionControl, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		// toggle loop
 		var newLoopMode domain.LoopMode
@@ -260,18 +276,13 @@ func commonComponentToggleLoop(srv *server.Server) func(*discordgo.Session, *dis
 		}
 		err = srv.UC.Queue.SetLoopMode(q, newLoopMode)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
-			return
-		}
-		err = srv.UC.Player.UpdateNPMessage(s, p, q, -1, false, true)
-		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -284,19 +295,19 @@ func commonComponentToggleShuffle(srv *server.Server) func(*discordgo.Session, *
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -308,6 +319,10 @@ func commonComponentToggleShuffle(srv *server.Server) func(*discordgo.Session, *
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionControl, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		// toggle shuffle
 		var newShuffleMode domain.ShuffleMode
@@ -319,18 +334,129 @@ func commonComponentToggleShuffle(srv *server.Server) func(*discordgo.Session, *
 		}
 		err = srv.UC.Queue.SetShuffleMode(q, newShuffleMode)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
+
+func commonComponentToggleFair(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// check if user in voice channel
+		vs, err := util.GetUserVS(s, i, true, "You have to be in a voice channel!")
+		if errors.Is(err, discordgo.ErrStateNotFound) {
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// get player and queue
+		p, err := srv.UC.Player.Get(i.GuildID)
+		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		if !util.IsPlayerReady(p) || len(q.ActiveTracks) == 0 {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNotPlaying)
+			return
+		}
+		if !util.IsSameVC(p, vs) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
+			return
+		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionControl, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
+
+		// toggle fair
+		var newFairMode domain.FairMode
+		switch q.Fair {
+		case domain.FairModeOff:
+			newFairMode = domain.FairModeOn
+		case domain.FairModeOn:
+			newFairMode = domain.FairModeOff
+		}
+		err = srv.UC.Queue.SetFairMode(q, newFairMode)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
-		err = srv.UC.Player.UpdateNPMessage(s, p, q, -1, false, true)
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
+
+func commonComponentToggleAutoplay(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// check if user in voice channel
+		vs, err := util.GetUserVS(s, i, true, "You have to be in a voice channel!")
+		if errors.Is(err, discordgo.ErrStateNotFound) {
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// get player and queue
+		p, err := srv.UC.Player.Get(i.GuildID)
+		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		if !util.IsPlayerReady(p) || len(q.ActiveTracks) == 0 {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNotPlaying)
+			return
+		}
+		if !util.IsSameVC(p, vs) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
+			return
+		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionControl, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
+
+		// toggle autoplay
+		var newAutoplayMode domain.AutoplayMode
+		switch q.Autoplay {
+		case domain.AutoplayModeOff:
+			newAutoplayMode = domain.AutoplayModeOn
+		case domain.AutoplayModeOn:
+			newAutoplayMode = domain.AutoplayModeOff
+		}
+		err = srv.UC.Queue.SetAutoplayMode(q, newAutoplayMode)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -0,0 +1,99 @@
+package caroline
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const npHereCommandName = "np-here"
+
+func RegisterNPHere(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        npHereCommandName,
+		Description: "Show now playing messages in this channel",
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[npHereCommandName] = npHereCommand(srv)
+
+	return nil
+}
+
+func npHereCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// check if user in voice channel
+		vs, err := util.GetUserVS(s, i, true, "You have to be in a voice channel to move the player!")
+		if errors.Is(err, discordgo.ErrStateNotFound) {
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// get player and queue
+		p, err := srv.UC.Player.Get(i.GuildID)
+		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		if !util.IsPlayerReady(p) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNotPlaying)
+			return
+		}
+		if !util.IsSameVC(p, vs) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
+			return
+		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionControl, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
+
+		if _, err := srv.UC.MusicChannel.Get(i.GuildID); err == nil {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Player message is pinned in the music channel!"))
+			return
+		}
+
+		npch, err := s.Channel(i.ChannelID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Description: "Now playing messages moved here!",
+						Color:       common.ColorAction,
+					},
+				},
+			},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+
+		// rebind after responding so the np message is the latest one
+		err = srv.UC.Player.MoveNPChannel(s, p, q, npch)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package caroline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+// npUpdateDebounce coalesces bursts of events, e.g. a playlist import, into a single message edit.
+const npUpdateDebounce = 250 * time.Millisecond
+
+func RegisterNPUpdater(srv *server.Server, _ map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	var (
+		pending = make(map[string]*time.Timer)
+		lock    sync.Mutex
+	)
+
+	srv.Events.Subscribe("np", func(e domain.Event) {
+		switch e.(type) {
+		case domain.TrackEnded:
+			// followed by TrackLoading or PlayerStateChanged
+			return
+		}
+
+		guildID := e.EventGuildID()
+		lock.Lock()
+		defer lock.Unlock()
+		if _, ok := pending[guildID]; ok {
+			return
+		}
+		pending[guildID] = time.AfterFunc(npUpdateDebounce, func() {
+			lock.Lock()
+			delete(pending, guildID)
+			lock.Unlock()
+
+			updateNPMessage(srv, guildID)
+		})
+	})
+
+	return nil
+}
+
+func updateNPMessage(srv *server.Server, guildID string) {
+	p, err := srv.UC.Player.Get(guildID)
+	if err != nil || !util.IsPlayerReady(p) {
+		return
+	}
+	q, err := srv.UC.Queue.Get(guildID)
+	if err != nil {
+		srv.Logger.Error("failed to update np message", "guild", guildID, "err", err)
+		return
+	}
+
+	err = srv.UC.Player.UpdateNPMessage(srv.GuildSession(guildID), p, q, -1, false, true)
+	if err != nil {
+		srv.Logger.Error("failed to update np message", "guild", guildID, "err", err)
+	}
+}
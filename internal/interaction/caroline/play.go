@@ -3,7 +3,6 @@ package caroline
 import (
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
@@ -52,35 +51,35 @@ func playCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if errors.Is(err, domain.ErrNotPlaying) {
 			vch, err := s.Channel(vs.ChannelID)
 			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+				srv.Log(i).Error("interaction failed", "err", err)
 				return
 			}
 			npch, err := s.Channel(i.ChannelID)
 			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+				srv.Log(i).Error("interaction failed", "err", err)
 				return
 			}
 			p, err = srv.UC.Player.Create(s, vch, npch, q)
 			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+				srv.Log(i).Error("interaction failed", "err", err)
 				return
 			}
 		} else if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -92,16 +91,16 @@ func playCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 		// parse query and position
 		query, ok := i.ApplicationCommandData().Options[0].Value.(string)
 		if !ok {
-			log.Printf("%s: %s: option type mismatch\n", i.Type, util.InteractionName(i))
+			srv.Log(i).Error("option type mismatch")
 			return
 		}
 		query = strings.TrimSpace(query)
 
-		endPos := -1
+		pos := -1
 		if len(i.ApplicationCommandData().Options) > 1 {
 			posRaw, ok := i.ApplicationCommandData().Options[1].Value.(string)
 			if !ok {
-				log.Printf("%s: %s: option type mismatch\n", i.Type, util.InteractionName(i))
+				srv.Log(i).Error("option type mismatch")
 				return
 			}
 			p, err := util.ParseRelativePosOption(q, posRaw)
@@ -109,7 +108,7 @@ func playCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 				_ = s.InteractionRespond(i.Interaction, common.InteractionResponseInvalidPosition)
 				return
 			}
-			endPos = p
+			pos = p
 		}
 
 		// initial response
@@ -125,25 +124,37 @@ func playCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 			},
 		})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 
 		// parse musics
 		meta, musics, err := srv.UC.Music.Parse(query, i.Member.User)
+		if errors.Is(err, domain.ErrSpotifyDisabled) {
+			_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{{Description: "Spotify links are not supported!", Color: common.ColorError}},
+			})
+			return
+		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// enqueue
-		for _, m := range musics {
-			endPos, err = srv.UC.Queue.Enqueue(q, m, endPos)
+		res := enqueueMusics(srv, q, musics, pos)
+		if res.err != nil {
+			srv.Log(i).Error("interaction failed", "err", res.err)
+		}
+		if len(res.Positions) == 0 {
+			_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{res.rejectionEmbed()},
+			})
 			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+				srv.Log(i).Error("interaction failed", "err", err)
 			}
-			endPos++
+			return
 		}
-		startPos := endPos - len(musics)
+		startPos, endPos := res.StartPos, res.EndPos
 
 		// respond queue summary
 		var resp *discordgo.MessageEmbed
@@ -151,7 +162,7 @@ func playCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 		case domain.MusicSourceSpotifyPlaylist:
 			resp = &discordgo.MessageEmbed{
 				Title:       "Added to Queue",
-				Description: fmt.Sprintf("**%d** items from **%s** %s added!", len(musics), meta, musics[0].Source),
+				Description: fmt.Sprintf("**%d** items from **%s** %s added!", len(res.Positions), meta, musics[0].Source),
 				Color:       common.ColorPlay,
 				Author: &discordgo.MessageEmbedAuthor{
 					Name:    i.Member.User.Username,
@@ -183,31 +194,75 @@ func playCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 				},
 			}
 		}
+		if res.Duplicates > 0 {
+			resp.Fields = append(resp.Fields, res.duplicatesField())
+		}
+		if res.Rejected > 0 {
+			resp.Fields = append(resp.Fields, res.rejectionField())
+		}
 		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 			Embeds: &[]*discordgo.MessageEmbed{resp},
 		})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 
 		if p.Status != domain.PlayerStatusPlaying {
 			// immediately play music (or first of the series when multiple are added) when player is not playing
 			err = srv.UC.Queue.Jump(q, startPos)
 			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+				srv.Log(i).Error("interaction failed", "err", err)
 				return
 			}
 		}
 		err = srv.UC.Player.Play(p)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
-		}
-		if p.Status == domain.PlayerStatusPlaying {
-			err = srv.UC.Player.UpdateNPMessage(s, p, q, -1, false, true)
-			if err != nil {
-				log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
-				return
-			}
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
+
+type enqueueResult struct {
+	*domain.EnqueueResult
+	err error
+}
+
+func (r enqueueResult) rejectionEmbed() *discordgo.MessageEmbed {
+	description := "Could not add to queue!"
+	switch {
+	case r.RejectErr != nil:
+		description = fmt.Sprintf("Could not add to queue, %s!", r.RejectErr)
+	case r.Duplicates > 0:
+		description = fmt.Sprintf("Could not add to queue, %s!", domain.ErrDuplicate)
+	}
+	return &discordgo.MessageEmbed{
+		Description: description,
+		Color:       common.ColorError,
+	}
+}
+
+func (r enqueueResult) duplicatesField() *discordgo.MessageEmbedField {
+	value := fmt.Sprintf("%d %s skipped", r.Duplicates, util.Plural("track", r.Duplicates))
+	if r.DuplicatePolicy == domain.DuplicatePolicyWarn {
+		value = fmt.Sprintf("%d %s already in queue", r.Duplicates, util.Plural("track", r.Duplicates))
+	}
+	return &discordgo.MessageEmbedField{
+		Name:   "Duplicates",
+		Value:  value,
+		Inline: false,
+	}
+}
+
+func (r enqueueResult) rejectionField() *discordgo.MessageEmbedField {
+	return &discordgo.MessageEmbedField{
+		Name:   "Rejected",
+		Value:  fmt.Sprintf("%d %s, %s", r.Rejected, util.Plural("track", r.Rejected), r.RejectErr),
+		Inline: false,
+	}
+}
+
+// enqueueMusics adds musics to the queue, keeping the unexpected enqueue error for the caller to log.
+func enqueueMusics(srv *server.Server, q *domain.Queue, musics []*domain.Music, pos int) enqueueResult {
+	res, err := srv.UC.Queue.EnqueueAll(q, musics, pos)
+	return enqueueResult{EnqueueResult: res, err: err}
+}
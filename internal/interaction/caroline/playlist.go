@@ -0,0 +1,409 @@
+package caroline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	playlistCommandName = "playlist"
+
+	playlistSubcommandSave   = "save"
+	playlistSubcommandLoad   = "load"
+	playlistSubcommandList   = "list"
+	playlistSubcommandDelete = "delete"
+	playlistSubcommandShow   = "show"
+
+	playlistShowMaxTracks = 20
+)
+
+func RegisterPlaylist(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	nameOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: "Playlist name",
+		Required:    true,
+	}
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        playlistCommandName,
+		Description: "Manage saved playlists",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        playlistSubcommandSave,
+				Description: "Save current queue as a playlist",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        playlistSubcommandLoad,
+				Description: "Add a saved playlist to queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "position",
+						Description: "Insert position",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        playlistSubcommandList,
+				Description: "List saved playlists",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        playlistSubcommandDelete,
+				Description: "Delete a saved playlist",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        playlistSubcommandShow,
+				Description: "Show tracks of a saved playlist",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[playlistCommandName] = playlistCommand(srv)
+
+	return nil
+}
+
+func playlistCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case playlistSubcommandSave:
+			playlistSave(srv, s, i, sub)
+		case playlistSubcommandLoad:
+			playlistLoad(srv, s, i, sub)
+		case playlistSubcommandList:
+			playlistList(srv, s, i)
+		case playlistSubcommandDelete:
+			playlistDelete(srv, s, i, sub)
+		case playlistSubcommandShow:
+			playlistShow(srv, s, i, sub)
+		default:
+			srv.Log(i).Error("unknown subcommand", "subcommand", sub.Name)
+		}
+	}
+}
+
+func playlistSave(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	// get queue
+	q, err := srv.UC.Queue.Get(i.GuildID)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	name, ok := sub.Options[0].Value.(string)
+	if !ok {
+		srv.Log(i).Error("option type mismatch")
+		return
+	}
+
+	// save playlist
+	pl, err := srv.UC.Playlist.Save(q, name, i.Member.User)
+	if errors.Is(err, domain.ErrPlaylistEmpty) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Queue is empty, nothing to save!"))
+		return
+	}
+	if errors.Is(err, domain.ErrBadFormat) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse(fmt.Sprintf("Playlist name must be 1 to %d characters long!", domain.PlaylistMaxNameLength)))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: fmt.Sprintf("Saved **%d** %s as playlist **%s**!", len(pl.Tracks), util.Plural("track", len(pl.Tracks)), pl.Name),
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func playlistLoad(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	// check if user in voice channel
+	vs, err := util.GetUserVS(s, i, true, "You have to be in a voice channel to play something!")
+	if errors.Is(err, discordgo.ErrStateNotFound) {
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	// get playlist
+	name, ok := sub.Options[0].Value.(string)
+	if !ok {
+		srv.Log(i).Error("option type mismatch")
+		return
+	}
+	pl, err := srv.UC.Playlist.Get(i.GuildID, name)
+	if errors.Is(err, domain.ErrPlaylistNotFound) || errors.Is(err, domain.ErrBadFormat) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse(fmt.Sprintf("Playlist **%s** not found!", name)))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	// get player and queue
+	q, err := srv.UC.Queue.Get(i.GuildID)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+	p, err := srv.UC.Player.Get(i.GuildID)
+	if errors.Is(err, domain.ErrNotPlaying) {
+		vch, err := s.Channel(vs.ChannelID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		npch, err := s.Channel(i.ChannelID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		p, err = srv.UC.Player.Create(s, vch, npch, q)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+	} else if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	if !util.IsSameVC(p, vs) {
+		_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
+		return
+	}
+
+	// parse position
+	pos := -1
+	if len(sub.Options) > 1 {
+		posRaw, ok := sub.Options[1].Value.(string)
+		if !ok {
+			srv.Log(i).Error("option type mismatch")
+			return
+		}
+		p, err := util.ParseRelativePosOption(q, posRaw)
+		if err != nil {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseInvalidPosition)
+			return
+		}
+		pos = p
+	}
+
+	// initial response, tracks may need loading to be checked against the server's limits
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: "Adding to queue...",
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+
+	// enqueue
+	res := enqueueMusics(srv, q, srv.UC.Playlist.Tracks(pl, i.Member.User), pos)
+	if res.err != nil {
+		srv.Log(i).Error("interaction failed", "err", res.err)
+	}
+	if len(res.Positions) == 0 {
+		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Embeds: &[]*discordgo.MessageEmbed{res.rejectionEmbed()},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+		return
+	}
+	startPos, endPos := res.StartPos, res.EndPos
+
+	resp := &discordgo.MessageEmbed{
+		Title:       "Added to Queue",
+		Description: fmt.Sprintf("**%d** items from playlist **%s** added!", len(res.Positions), pl.Name),
+		Color:       common.ColorPlay,
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    i.Member.User.Username,
+			IconURL: discordgo.EndpointUserAvatar(i.Member.User.ID, i.Member.User.Avatar),
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Position",
+				Value:  fmt.Sprintf("%d to %d of %d", startPos+1, endPos, len(q.ActiveTracks)),
+				Inline: true,
+			},
+		},
+	}
+	if res.Duplicates > 0 {
+		resp.Fields = append(resp.Fields, res.duplicatesField())
+	}
+	if res.Rejected > 0 {
+		resp.Fields = append(resp.Fields, res.rejectionField())
+	}
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{resp},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+
+	if p.Status != domain.PlayerStatusPlaying {
+		// immediately play the first loaded track when player is not playing
+		err = srv.UC.Queue.Jump(q, startPos)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+	}
+	err = srv.UC.Player.Play(p)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func playlistList(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	pls, err := srv.UC.Playlist.List(i.GuildID)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	description := "_No saved playlists_"
+	if len(pls) > 0 {
+		builder := strings.Builder{}
+		for _, pl := range pls {
+			builder.WriteString(fmt.Sprintf("**%s** — %d %s, %s\n",
+				pl.Name, len(pl.Tracks), util.Plural("track", len(pl.Tracks)), pl.Duration().Round(time.Second)))
+		}
+		description = builder.String()
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:       "Playlists",
+					Description: description,
+					Color:       common.ColorQueue,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func playlistDelete(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	name, ok := sub.Options[0].Value.(string)
+	if !ok {
+		srv.Log(i).Error("option type mismatch")
+		return
+	}
+
+	pl, err := srv.UC.Playlist.Get(i.GuildID, name)
+	if errors.Is(err, domain.ErrPlaylistNotFound) || errors.Is(err, domain.ErrBadFormat) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse(fmt.Sprintf("Playlist **%s** not found!", name)))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	// only DJs and the playlist creator may delete it
+	if pl.CreatedByID != i.Member.User.ID && !srv.UC.Permission.IsDJ(s, i.GuildID, i.Member) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Only DJs or the playlist creator can delete it!"))
+		return
+	}
+
+	err = srv.UC.Playlist.Delete(i.GuildID, pl.Name)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: fmt.Sprintf("Deleted playlist **%s**!", pl.Name),
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func playlistShow(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	name, ok := sub.Options[0].Value.(string)
+	if !ok {
+		srv.Log(i).Error("option type mismatch")
+		return
+	}
+
+	pl, err := srv.UC.Playlist.Get(i.GuildID, name)
+	if errors.Is(err, domain.ErrPlaylistNotFound) || errors.Is(err, domain.ErrBadFormat) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse(fmt.Sprintf("Playlist **%s** not found!", name)))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: util.BuildPlaylistEmbed(pl, playlistShowMaxTracks),
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
@@ -3,7 +3,6 @@ package caroline
 import (
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -45,19 +44,19 @@ func removeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Inter
 			return
 		}
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
 		// get player and queue
 		p, err := srv.UC.Player.Get(i.GuildID)
 		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 		q, err := srv.UC.Queue.Get(i.GuildID)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -73,7 +72,7 @@ func removeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Inter
 		// parse pos
 		posRaw, ok := i.ApplicationCommandData().Options[0].Value.(string)
 		if !ok {
-			log.Printf("%s: %s: option type mismatch\n", i.Type, util.InteractionName(i))
+			srv.Log(i).Error("option type mismatch")
 			return
 		}
 		pos, err := util.ParseRelativePosOption(q, posRaw)
@@ -81,6 +80,10 @@ func removeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Inter
 			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseInvalidPosition)
 			return
 		}
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionRemove, q.ActiveTracks[pos]) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
 
 		if pos == q.CurrentPos {
 			_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -100,7 +103,7 @@ func removeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Inter
 		// remove from queue
 		err = srv.UC.Queue.Remove(q, pos)
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 			return
 		}
 
@@ -116,13 +119,7 @@ func removeCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Inter
 			},
 		})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
-		}
-
-		err = srv.UC.Player.UpdateNPMessage(s, p, q, -1, false, true)
-		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
-			return
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
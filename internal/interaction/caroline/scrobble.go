@@ -0,0 +1,276 @@
+package caroline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	scrobbleCommandName = "scrobble"
+
+	scrobbleSubcommandLink   = "link"
+	scrobbleSubcommandUnlink = "unlink"
+	scrobbleSubcommandStatus = "status"
+
+	listenBrainzSettingsURL = "https://listenbrainz.org/settings/"
+)
+
+func RegisterScrobble(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	serviceChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(domain.ScrobbleServices))
+	for _, service := range domain.ScrobbleServices {
+		serviceChoices = append(serviceChoices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  service.String(),
+			Value: string(service),
+		})
+	}
+
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        scrobbleCommandName,
+		Description: "Scrobble tracks you listen to",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        scrobbleSubcommandLink,
+				Description: "Link a scrobbling account",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "service",
+						Description: "Scrobbling service",
+						Required:    true,
+						Choices:     serviceChoices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "token",
+						Description: "ListenBrainz user token",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        scrobbleSubcommandUnlink,
+				Description: "Unlink a scrobbling account",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "service",
+						Description: "Scrobbling service",
+						Required:    true,
+						Choices:     serviceChoices,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        scrobbleSubcommandStatus,
+				Description: "Show linked scrobbling accounts",
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[scrobbleCommandName] = scrobbleCommand(srv)
+	interactionHandlers[common.ScrobbleComponentLinkLastFMID] = scrobbleComponentLinkLastFM(srv)
+	srv.Events.Subscribe("scrobble", scrobbleEvent(srv))
+
+	return nil
+}
+
+func scrobbleCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case scrobbleSubcommandLink:
+			scrobbleLink(srv, s, i, sub)
+		case scrobbleSubcommandUnlink:
+			scrobbleUnlink(srv, s, i, sub)
+		case scrobbleSubcommandStatus:
+			scrobbleStatus(srv, s, i)
+		default:
+			srv.Log(i).Error("unknown subcommand", "subcommand", sub.Name)
+		}
+	}
+}
+
+func scrobbleLink(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	service := domain.ScrobbleService(sub.Options[0].StringValue())
+	if !srv.UC.Scrobble.Enabled(service) {
+		_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse(fmt.Sprintf("%s scrobbling is not available!", service), common.ColorError))
+		return
+	}
+
+	switch service {
+	case domain.ScrobbleServiceLastFM:
+		authURL, err := srv.UC.Scrobble.Authorize(i.Member.User.ID, service)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse("Could not reach Last.fm, try again later!", common.ColorError))
+			return
+		}
+
+		resp := buildScrobbleResponse("Authorize Caroline on Last.fm, then press **Done**.", common.ColorAction)
+		resp.Data.Components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label: "Authorize",
+						Style: discordgo.LinkButton,
+						URL:   authURL,
+					},
+					discordgo.Button{
+						Label:    "Done",
+						Style:    discordgo.PrimaryButton,
+						CustomID: common.ScrobbleComponentLinkLastFMID,
+					},
+				},
+			},
+		}
+		err = s.InteractionRespond(i.Interaction, resp)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+
+	case domain.ScrobbleServiceListenBrainz:
+		if len(sub.Options) < 2 || strings.TrimSpace(sub.Options[1].StringValue()) == "" {
+			_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse(fmt.Sprintf("Copy your user token from %s and pass it as `token`!", listenBrainzSettingsURL), common.ColorError))
+			return
+		}
+		account, err := srv.UC.Scrobble.Link(i.Member.User.ID, service, strings.TrimSpace(sub.Options[1].StringValue()))
+		if errors.Is(err, domain.ErrScrobbleUnauthorized) {
+			_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse("Invalid ListenBrainz user token!", common.ColorError))
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse("Could not reach ListenBrainz, try again later!", common.ColorError))
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, buildScrobbleResponse(fmt.Sprintf("Scrobbling to ListenBrainz as **%s**!", account.Username), common.ColorAction))
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
+
+func scrobbleComponentLinkLastFM(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		account, err := srv.UC.Scrobble.Link(i.Member.User.ID, domain.ScrobbleServiceLastFM, "")
+		if errors.Is(err, domain.ErrScrobbleUnauthorized) {
+			_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse("Caroline is not authorized yet, press **Authorize** first!", common.ColorError))
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse("Could not reach Last.fm, try again later!", common.ColorError))
+			return
+		}
+
+		resp := buildScrobbleResponse(fmt.Sprintf("Scrobbling to Last.fm as **%s**!", account.Username), common.ColorAction)
+		resp.Type = discordgo.InteractionResponseUpdateMessage
+		resp.Data.Components = []discordgo.MessageComponent{}
+		err = s.InteractionRespond(i.Interaction, resp)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
+
+func scrobbleUnlink(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	service := domain.ScrobbleService(sub.Options[0].StringValue())
+	_, err := srv.UC.Scrobble.Unlink(i.Member.User.ID, service)
+	if errors.Is(err, domain.ErrScrobbleNotFound) {
+		_ = s.InteractionRespond(i.Interaction, buildScrobbleResponse(fmt.Sprintf("No %s account linked!", service), common.ColorError))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, buildScrobbleResponse(fmt.Sprintf("%s account unlinked!", service), common.ColorAction))
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func scrobbleStatus(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	accounts, err := srv.UC.Scrobble.List(i.Member.User.ID)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	builder := strings.Builder{}
+	for _, a := range accounts {
+		builder.WriteString(fmt.Sprintf("**%s**: %s, linked <t:%d:R>\n", a.Service, a.Username, a.LinkedAt.Unix()))
+	}
+	if len(accounts) == 0 {
+		builder.WriteString("No accounts linked, use `/scrobble link` to start scrobbling!")
+	}
+
+	err = s.InteractionRespond(i.Interaction, buildScrobbleResponse(builder.String(), common.ColorQueue))
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+// buildScrobbleResponse builds an ephemeral response, accounts are personal.
+func buildScrobbleResponse(msg string, color int) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: msg,
+					Color:       color,
+				},
+			},
+		},
+	}
+}
+
+// scrobbleEvent submits tracks for the listeners in the voice channel.
+func scrobbleEvent(srv *server.Server) func(domain.Event) {
+	return func(e domain.Event) {
+		switch e := e.(type) {
+		case domain.TrackStarted:
+			srv.UC.Scrobble.NowPlaying(scrobbleListeners(srv, e.GuildID, e.VoiceChannelID), e.Music)
+		case domain.TrackEnded:
+			if e.Paused {
+				// scrobbled once the play ends
+				return
+			}
+			srv.UC.Scrobble.Scrobble(scrobbleListeners(srv, e.GuildID, e.VoiceChannelID), e.Music, time.Now().Add(-e.Listened), e.Listened)
+		}
+	}
+}
+
+// scrobbleListeners returns the listeners currently in the voice channel, deafened users are not listening.
+func scrobbleListeners(srv *server.Server, guildID, channelID string) []string {
+	s := srv.GuildSession(guildID)
+	listeners := make([]string, 0)
+	for _, userID := range util.ListenerIDs(s, guildID, channelID) {
+		vs, err := s.State.VoiceState(guildID, userID)
+		if err != nil || vs.Deaf || vs.SelfDeaf {
+			continue
+		}
+		listeners = append(listeners, userID)
+	}
+
+	return listeners
+}
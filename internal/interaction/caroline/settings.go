@@ -0,0 +1,194 @@
+package caroline
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	settingsCommandName = "settings"
+
+	settingsSubcommandView  = "view"
+	settingsSubcommandSet   = "set"
+	settingsSubcommandReset = "reset"
+)
+
+func RegisterSettings(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	keyChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(domain.SettingKeys))
+	for _, key := range domain.SettingKeys {
+		keyChoices = append(keyChoices, &discordgo.ApplicationCommandOptionChoice{Name: string(key), Value: string(key)})
+	}
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        settingsCommandName,
+		Description: "Manage server settings",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        settingsSubcommandView,
+				Description: "Show current settings",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        settingsSubcommandSet,
+				Description: "Change a setting",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "key",
+						Description: "Setting name",
+						Required:    true,
+						Choices:     keyChoices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "value",
+						Description: "New value, use none to clear roles and channels",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        settingsSubcommandReset,
+				Description: "Reset a setting, or all settings, to default",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "key",
+						Description: "Setting name",
+						Required:    false,
+						Choices:     keyChoices,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[settingsCommandName] = settingsCommand(srv)
+
+	return nil
+}
+
+func settingsCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		sub := i.ApplicationCommandData().Options[0]
+		if sub.Name != settingsSubcommandView && !srv.UC.Permission.IsDJ(s, i.GuildID, i.Member) {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Only DJs can change settings!"))
+			return
+		}
+
+		switch sub.Name {
+		case settingsSubcommandView:
+			settingsView(srv, s, i)
+		case settingsSubcommandSet:
+			settingsSet(srv, s, i, sub)
+		case settingsSubcommandReset:
+			settingsReset(srv, s, i, sub)
+		default:
+			srv.Log(i).Error("unknown subcommand", "subcommand", sub.Name)
+		}
+	}
+}
+
+func settingsView(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	g, err := srv.UC.Settings.Get(i.GuildID)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: util.BuildSettingsEmbed(g),
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func settingsSet(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	key := domain.SettingKey(sub.Options[0].StringValue())
+	value := sub.Options[1].StringValue()
+
+	g, err := srv.UC.Settings.Set(i.GuildID, key, value)
+	if errors.Is(err, domain.ErrBadFormat) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse(fmt.Sprintf("Invalid value for `%s`!", key)))
+		return
+	}
+	if errors.Is(err, domain.ErrSettingNotFound) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Unknown setting!"))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: fmt.Sprintf("`%s` set to **%s**!", key, g.Value(key)),
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func settingsReset(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	var (
+		g           *domain.GuildSettings
+		err         error
+		description string
+	)
+	if len(sub.Options) > 0 {
+		key := domain.SettingKey(sub.Options[0].StringValue())
+		g, err = srv.UC.Settings.Reset(i.GuildID, key)
+		if err == nil {
+			description = fmt.Sprintf("`%s` reset to **%s**!", key, g.Value(key))
+		}
+	} else {
+		_, err = srv.UC.Settings.ResetAll(i.GuildID)
+		description = "All settings reset to default!"
+	}
+	if errors.Is(err, domain.ErrSettingNotFound) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Unknown setting!"))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: description,
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
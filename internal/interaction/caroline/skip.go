@@ -0,0 +1,228 @@
+package caroline
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const skipCommandName = "skip"
+
+func RegisterSkip(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        skipCommandName,
+		Description: "Skip or vote to skip current track",
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[skipCommandName] = skipCommand(srv)
+	interactionHandlers[common.VoteComponentSkipID] = voteComponentSkip(srv)
+
+	return nil
+}
+
+func skipCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// check if user in voice channel
+		vs, err := util.GetUserVS(s, i, true, "You have to be in a voice channel to skip!")
+		if errors.Is(err, discordgo.ErrStateNotFound) {
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// get player and queue
+		p, err := srv.UC.Player.Get(i.GuildID)
+		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		if !util.IsPlayerReady(p) || q.NowPlaying() == nil {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNotPlaying)
+			return
+		}
+		if !util.IsSameVC(p, vs) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
+			return
+		}
+
+		switch srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionSkip, q.NowPlaying()) {
+		case domain.PermissionDenied:
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		case domain.PermissionVoteRequired:
+			voteSkip(srv, s, i, p, q, discordgo.InteractionResponseChannelMessageWithSource)
+			return
+		}
+
+		// skip track
+		err = skipTrack(srv, p, q)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Description: "Skipped!",
+						Color:       common.ColorAction,
+					},
+				},
+			},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
+
+func voteComponentSkip(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// check if user in voice channel
+		vs, err := util.GetUserVS(s, i, true, "You have to be in a voice channel to vote!")
+		if errors.Is(err, discordgo.ErrStateNotFound) {
+			return
+		}
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// get player and queue
+		p, err := srv.UC.Player.Get(i.GuildID)
+		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// the vote is on the track that was playing when it started
+		if music := q.NowPlaying(); music == nil || music.ID != util.InteractionArg(i) {
+			endVote(srv, s, i)
+			return
+		}
+		if !util.IsPlayerReady(p) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNotPlaying)
+			return
+		}
+		if !util.IsSameVC(p, vs) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseDifferentVC)
+			return
+		}
+
+		switch srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionSkip, q.NowPlaying()) {
+		case domain.PermissionDenied:
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		case domain.PermissionVoteRequired:
+			voteSkip(srv, s, i, p, q, discordgo.InteractionResponseUpdateMessage)
+			return
+		}
+
+		// DJs and the requester skip without waiting for the vote
+		err = skipTrack(srv, p, q)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Description: "Skipped!",
+						Color:       common.ColorAction,
+					},
+				},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
+
+// voteSkip casts the user's vote on the current track and skips it once enough listeners have voted.
+func voteSkip(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, p *domain.Player, q *domain.Queue, respType discordgo.InteractionResponseType) {
+	music := q.NowPlaying()
+	votes, required, passed, err := srv.UC.Permission.VoteSkip(s, p, q, i.Member)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	data := &discordgo.InteractionResponseData{
+		Embeds:     util.BuildVoteSkipEmbed(music, votes, required),
+		Components: util.BuildVoteSkipComponent(music),
+	}
+	if passed {
+		err = skipTrack(srv, p, q)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		data.Embeds[0].Title = "Skipped by Vote"
+		data.Components = []discordgo.MessageComponent{}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: respType,
+		Data: data,
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+// endVote disables the vote button of a track that is no longer playing.
+func endVote(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	embs := make([]*discordgo.MessageEmbed, 0, len(i.Message.Embeds))
+	for _, e := range i.Message.Embeds {
+		emb := *e
+		emb.Title = "Vote Ended"
+		embs = append(embs, &emb)
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     embs,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func skipTrack(srv *server.Server, p *domain.Player, q *domain.Queue) error {
+	err := srv.UC.Queue.Jump(q, (q.CurrentPos+1)%len(q.ActiveTracks))
+	if err != nil {
+		return err
+	}
+	return srv.UC.Player.Skip(p)
+}
@@ -2,8 +2,8 @@ package caroline
 
 import (
 	"fmt"
-	"log"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -36,56 +36,92 @@ func statCommand(srv *server.Server) func(*discordgo.Session, *discordgo.Interac
 		var m runtime.MemStats
 		runtime.ReadMemStats(&m)
 
+		// aggregate shards run by this process
+		var (
+			guilds  int
+			latency time.Duration
+			shards  strings.Builder
+		)
+		stats := srv.ShardStats()
+		for _, st := range stats {
+			guilds += st.Guilds
+			latency += st.Latency
+			shards.WriteString(fmt.Sprintf("`#%d` %d %s, %d %s, %dms\n",
+				st.ID, st.Guilds, util.Plural("server", st.Guilds), st.Speakers, util.Plural("speaker", st.Speakers), st.Latency.Milliseconds()))
+		}
+		latency /= time.Duration(len(stats))
+
 		// respond
+		emb := &discordgo.MessageEmbed{
+			Title:       "About Me",
+			Description: "Hi! I'm Caroline!\nhttps://github.com/daystram/caroline",
+			Color:       common.ColorBrand,
+			Fields: []*discordgo.MessageEmbedField{
+				{
+					Name:   "Version",
+					Value:  config.Version(),
+					Inline: true,
+				},
+				{
+					Name:   "Heap Size",
+					Value:  fmt.Sprintf("%d MiB", m.Alloc/1024/1024),
+					Inline: true,
+				},
+				{
+					Name:   "Goroutines",
+					Value:  fmt.Sprintf("%d", runtime.NumGoroutine()),
+					Inline: true,
+				},
+				{
+					Name:   "Servers",
+					Value:  fmt.Sprintf("%d", guilds),
+					Inline: true,
+				},
+				{
+					Name:   "Latency",
+					Value:  fmt.Sprintf("%dms", latency.Milliseconds()),
+					Inline: true,
+				},
+				{
+					Name:   "Speakers",
+					Value:  fmt.Sprintf("%d", srv.UC.Player.Count()),
+					Inline: true,
+				},
+				{
+					Name:   "Total Playtime",
+					Value:  srv.UC.Player.TotalPlaytime().Round(time.Second).String(),
+					Inline: true,
+				},
+				{
+					Name:   "Uptime",
+					Value:  time.Since(srv.StartTime).Round(time.Second).String(),
+					Inline: true,
+				},
+			},
+			Thumbnail: &discordgo.MessageEmbedThumbnail{
+				URL: discordgo.EndpointUserAvatar(srv.Session.State.User.ID, srv.Session.State.User.Avatar),
+			},
+		}
+		if srv.ShardCount > 1 {
+			name := "Shards"
+			if !srv.OwnsAllShards() {
+				// other shards run in other processes
+				name = fmt.Sprintf("Shards (%d of %d)", len(stats), srv.ShardCount)
+			}
+			emb.Fields = append(emb.Fields, &discordgo.MessageEmbedField{
+				Name:  name,
+				Value: shards.String(),
+			})
+		}
+
 		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Embeds: []*discordgo.MessageEmbed{
-					{
-						Title:       "About Me",
-						Description: "Hi! I'm Caroline!\nhttps://github.com/daystram/caroline",
-						Color:       common.ColorBrand,
-						Fields: []*discordgo.MessageEmbedField{
-							{
-								Name:   "Version",
-								Value:  config.Version(),
-								Inline: true,
-							},
-							{
-								Name:   "Heap Size",
-								Value:  fmt.Sprintf("%d MiB", m.Alloc/1024/1024),
-								Inline: true,
-							},
-							{
-								Name:   "Goroutines",
-								Value:  fmt.Sprintf("%d", runtime.NumGoroutine()),
-								Inline: true,
-							},
-							{
-								Name:   "Speakers",
-								Value:  fmt.Sprintf("%d", srv.UC.Player.Count()),
-								Inline: true,
-							},
-							{
-								Name:   "Total Playtime",
-								Value:  srv.UC.Player.TotalPlaytime().Round(time.Second).String(),
-								Inline: true,
-							},
-							{
-								Name:   "Uptime",
-								Value:  time.Since(srv.StartTime).Round(time.Second).String(),
-								Inline: true,
-							},
-						},
-						Thumbnail: &discordgo.MessageEmbedThumbnail{
-							URL: discordgo.EndpointUserAvatar(srv.Session.State.User.ID, srv.Session.State.User.Avatar),
-						},
-					},
-				},
+				Embeds: []*discordgo.MessageEmbed{emb},
 			},
 		})
 		if err != nil {
-			log.Printf("%s: %s: %s\n", i.Type, util.InteractionName(i), err)
+			srv.Log(i).Error("interaction failed", "err", err)
 		}
 	}
 }
@@ -0,0 +1,118 @@
+package caroline
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const summonCommandName = "summon"
+
+func RegisterSummon(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        summonCommandName,
+		Description: "Move player to your voice channel",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         "channel",
+				Description:  "Target voice channel",
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice, discordgo.ChannelTypeGuildStageVoice},
+				Required:     false,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[summonCommandName] = summonCommand(srv)
+
+	return nil
+}
+
+func summonCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		var channelOpt *discordgo.ApplicationCommandInteractionDataOption
+		if opts := i.ApplicationCommandData().Options; len(opts) > 0 {
+			channelOpt = opts[0]
+		}
+
+		// check if user in voice channel, unless a channel is given
+		vs, err := util.GetUserVS(s, i, channelOpt == nil, "You have to be in a voice channel to summon!")
+		if channelOpt == nil && errors.Is(err, discordgo.ErrStateNotFound) {
+			return
+		}
+		if err != nil && !errors.Is(err, discordgo.ErrStateNotFound) {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		// get player and queue
+		p, err := srv.UC.Player.Get(i.GuildID)
+		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+		q, err := srv.UC.Queue.Get(i.GuildID)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			return
+		}
+
+		if !util.IsPlayerReady(p) {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNotPlaying)
+			return
+		}
+
+		// resolve target channel
+		var vch *discordgo.Channel
+		if channelOpt != nil {
+			vch = channelOpt.ChannelValue(s)
+		} else {
+			vch, err = s.Channel(vs.ChannelID)
+		}
+		if err != nil || vch == nil || vch.GuildID != i.GuildID || (vch.Type != discordgo.ChannelTypeGuildVoice && vch.Type != discordgo.ChannelTypeGuildStageVoice) {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Invalid voice channel!"))
+			return
+		}
+		if vch.ID == p.VoiceChannel.ID {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Already in that voice channel!"))
+			return
+		}
+
+		if srv.UC.Permission.Check(s, p, q, i.Member, domain.PermissionActionSummon, nil) != domain.PermissionGranted {
+			_ = s.InteractionRespond(i.Interaction, common.InteractionResponseNoPermission)
+			return
+		}
+
+		// move player
+		err = srv.UC.Player.MoveVoiceChannel(p, vch)
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Failed moving to voice channel!"))
+			return
+		}
+
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Description: fmt.Sprintf("Moved to <#%s>!", vch.ID),
+						Color:       common.ColorAction,
+					},
+				},
+			},
+		})
+		if err != nil {
+			srv.Log(i).Error("interaction failed", "err", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package caroline
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+func RegisterVoiceState(srv *server.Server, _ map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	srv.AddHandler(voiceStateUpdate(srv))
+
+	return nil
+}
+
+func voiceStateUpdate(srv *server.Server) func(*discordgo.Session, *discordgo.VoiceStateUpdate) {
+	return func(s *discordgo.Session, vsu *discordgo.VoiceStateUpdate) {
+		p, err := srv.UC.Player.Get(vsu.GuildID)
+		if err != nil || !util.IsPlayerReady(p) {
+			return
+		}
+
+		vlog := srv.Logger.With("guild", vsu.GuildID, "user", vsu.UserID, "type", "voice state update")
+
+		// follow the bot being moved or disconnected by moderators
+		if vsu.UserID == s.State.User.ID {
+			q, err := srv.UC.Queue.Get(vsu.GuildID)
+			if err != nil {
+				vlog.Error("voice state update failed", "err", err)
+				return
+			}
+			err = srv.UC.Player.UpdateVoiceState(s, p, q, vsu.VoiceState)
+			if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+				vlog.Error("voice state update failed", "err", err)
+			}
+			if !util.IsPlayerReady(p) {
+				return
+			}
+		}
+
+		// only joins and leaves of the player's channel matter
+		beforeChannelID := ""
+		if vsu.BeforeUpdate != nil {
+			beforeChannelID = vsu.BeforeUpdate.ChannelID
+		}
+		if vsu.ChannelID == beforeChannelID || (vsu.ChannelID != p.VoiceChannel.ID && beforeChannelID != p.VoiceChannel.ID) {
+			return
+		}
+
+		q, err := srv.UC.Queue.Get(vsu.GuildID)
+		if err != nil {
+			vlog.Error("voice state update failed", "err", err)
+			return
+		}
+		err = srv.UC.Player.UpdateIdle(s, p, q)
+		if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+			vlog.Error("voice state update failed", "err", err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package caroline
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+// stageTopicMaxLength is the longest Stage instance topic Discord accepts.
+const stageTopicMaxLength = 120
+
+// voiceStatus is the status set on a guild's voice channel, and the Stage topic to restore afterwards.
+type voiceStatus struct {
+	channelID string
+	stage     bool
+	title     string
+	topic     string
+}
+
+func RegisterVoiceStatus(srv *server.Server, _ map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	// only accessed from the subscriber goroutine
+	statuses := make(map[string]*voiceStatus)
+
+	srv.Events.Subscribe("voice_status", func(e domain.Event) {
+		switch e := e.(type) {
+		case domain.TrackStarted:
+			title := e.Music.Query
+			if e.Music.Loaded {
+				title = e.Music.Title
+			}
+			setVoiceStatus(srv, statuses, e.GuildID, e.VoiceChannelID, title)
+		case domain.PlayerStateChanged:
+			vs, ok := statuses[e.GuildID]
+			if !ok {
+				return
+			}
+			if e.Status != domain.PlayerStatusPlaying {
+				clearVoiceStatus(srv, statuses, e.GuildID)
+				return
+			}
+			if vs.channelID != e.VoiceChannelID {
+				// follow the player to its new channel
+				setVoiceStatus(srv, statuses, e.GuildID, e.VoiceChannelID, vs.title)
+			}
+		}
+	})
+
+	return nil
+}
+
+func setVoiceStatus(srv *server.Server, statuses map[string]*voiceStatus, guildID, channelID, title string) {
+	s := srv.GuildSession(guildID)
+	vs, ok := statuses[guildID]
+	if ok && vs.channelID == channelID && vs.title == title {
+		return
+	}
+	if ok && vs.channelID != channelID {
+		clearVoiceStatus(srv, statuses, guildID)
+		ok = false
+	}
+	if !ok {
+		ch, err := s.State.Channel(channelID)
+		if err != nil {
+			ch, err = s.Channel(channelID)
+		}
+		if err != nil {
+			srv.Logger.Warn("voice status: failed to get channel", "guild", guildID, "channel", channelID, "err", err)
+			return
+		}
+		vs = &voiceStatus{
+			channelID: channelID,
+			stage:     ch.Type == discordgo.ChannelTypeGuildStageVoice,
+		}
+		statuses[guildID] = vs
+	}
+	vs.title = title
+
+	var err error
+	if vs.stage {
+		// only update a Stage that is live, keeping its original topic to restore
+		var si *discordgo.StageInstance
+		si, err = s.StageInstance(channelID)
+		if err == nil {
+			if vs.topic == "" {
+				vs.topic = si.Topic
+			}
+			_, err = s.StageInstanceEdit(channelID, &discordgo.StageInstanceParams{Topic: util.Truncate(title, stageTopicMaxLength)})
+		}
+	} else {
+		err = util.SetVoiceChannelStatus(s, channelID, util.Truncate(title, util.VoiceStatusMaxLength))
+	}
+	if err != nil {
+		logVoiceStatusError(srv, guildID, channelID, err)
+	}
+}
+
+func clearVoiceStatus(srv *server.Server, statuses map[string]*voiceStatus, guildID string) {
+	s := srv.GuildSession(guildID)
+	vs, ok := statuses[guildID]
+	if !ok {
+		return
+	}
+	delete(statuses, guildID)
+
+	var err error
+	if vs.stage {
+		if vs.topic == "" {
+			return
+		}
+		_, err = s.StageInstanceEdit(vs.channelID, &discordgo.StageInstanceParams{Topic: vs.topic})
+	} else {
+		err = util.SetVoiceChannelStatus(s, vs.channelID, "")
+	}
+	if err != nil {
+		logVoiceStatusError(srv, guildID, vs.channelID, err)
+	}
+}
+
+// logVoiceStatusError logs quietly when the bot lacks permissions or the Stage has ended, both are expected.
+func logVoiceStatusError(srv *server.Server, guildID, channelID string, err error) {
+	level := logger.LevelWarn
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil &&
+		(restErr.Response.StatusCode == http.StatusForbidden || restErr.Response.StatusCode == http.StatusNotFound) {
+		level = logger.LevelDebug
+	}
+	srv.Logger.Log(level, "voice status: update failed", "guild", guildID, "channel", channelID, "err", err)
+}
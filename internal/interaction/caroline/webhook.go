@@ -0,0 +1,232 @@
+package caroline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/server"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	webhookCommandName = "webhook"
+
+	webhookSubcommandAdd    = "add"
+	webhookSubcommandRemove = "remove"
+	webhookSubcommandList   = "list"
+)
+
+func RegisterWebhook(srv *server.Server, interactionHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) error {
+	_, err := srv.Session.ApplicationCommandCreate(srv.Session.State.User.ID, srv.DebugGuildID, &discordgo.ApplicationCommand{
+		Name:        webhookCommandName,
+		Description: "Manage webhooks notified when tracks start and end",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        webhookSubcommandAdd,
+				Description: "Register a webhook",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "HTTP(S) URL to POST track events to",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        webhookSubcommandRemove,
+				Description: "Remove a webhook",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "id",
+						Description: "Webhook ID",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        webhookSubcommandList,
+				Description: "List registered webhooks",
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	interactionHandlers[webhookCommandName] = webhookCommand(srv)
+	srv.Events.Subscribe("webhooks", webhookEvent(srv))
+
+	return nil
+}
+
+func webhookCommand(srv *server.Server) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// webhooks send guild activity elsewhere, unlike other settings this is not left to DJs
+		if i.Member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) == 0 {
+			_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Only server managers can manage webhooks!"))
+			return
+		}
+
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case webhookSubcommandAdd:
+			webhookAdd(srv, s, i, sub)
+		case webhookSubcommandRemove:
+			webhookRemove(srv, s, i, sub)
+		case webhookSubcommandList:
+			webhookList(srv, s, i)
+		default:
+			srv.Log(i).Error("unknown subcommand", "subcommand", sub.Name)
+		}
+	}
+}
+
+func webhookAdd(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	wh, err := srv.UC.Webhook.Add(i.GuildID, strings.TrimSpace(sub.Options[0].StringValue()), i.Member.User)
+	if errors.Is(err, domain.ErrBadFormat) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Invalid URL, only HTTP(S) URLs are supported!"))
+		return
+	}
+	if errors.Is(err, domain.ErrWebhookLimit) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Too many webhooks, remove one first!"))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	// the secret is only ever shown here
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:       "Webhook Added",
+					Description: fmt.Sprintf("Track events will be posted to `%s`.\nVerify deliveries with the `X-Caroline-Signature` header using this secret, it will not be shown again!", wh.URL),
+					Color:       common.ColorAction,
+					Fields: []*discordgo.MessageEmbedField{
+						{
+							Name:   "ID",
+							Value:  fmt.Sprintf("`%s`", wh.ID),
+							Inline: true,
+						},
+						{
+							Name:  "Secret",
+							Value: fmt.Sprintf("||`%s`||", wh.Secret),
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func webhookRemove(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	wh, err := srv.UC.Webhook.Remove(i.GuildID, strings.TrimSpace(sub.Options[0].StringValue()))
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		_ = s.InteractionRespond(i.Interaction, util.BuildErrorResponse("Webhook not found!"))
+		return
+	}
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: fmt.Sprintf("Webhook `%s` removed!", wh.ID),
+					Color:       common.ColorAction,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+func webhookList(srv *server.Server, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	whs, err := srv.UC.Webhook.List(i.GuildID)
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+		return
+	}
+
+	builder := strings.Builder{}
+	for _, wh := range whs {
+		builder.WriteString(fmt.Sprintf("`%s` %s\n_added by <@%s> <t:%d:R>_\n", wh.ID, wh.URL, wh.CreatedByID, wh.CreatedAt.Unix()))
+	}
+	if len(whs) == 0 {
+		builder.WriteString("No webhooks registered!")
+	}
+
+	// URLs may embed credentials, keep the list private
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:       "Webhooks",
+					Description: builder.String(),
+					Color:       common.ColorQueue,
+				},
+			},
+		},
+	})
+	if err != nil {
+		srv.Log(i).Error("interaction failed", "err", err)
+	}
+}
+
+// webhookEvent forwards track events to the guild's webhooks.
+func webhookEvent(srv *server.Server) func(domain.Event) {
+	return func(e domain.Event) {
+		d := &domain.WebhookDelivery{
+			GuildID: e.EventGuildID(),
+			Time:    time.Now(),
+		}
+		switch e := e.(type) {
+		case domain.TrackStarted:
+			if e.Resumed {
+				return
+			}
+			d.Event, d.Music, d.Position = domain.WebhookEventTrackStarted, e.Music, e.Offset
+		case domain.TrackEnded:
+			if e.Paused {
+				return
+			}
+			d.Event, d.Music, d.Position = domain.WebhookEventTrackEnded, e.Music, e.Elapsed
+			if e.Skipped {
+				d.Event = domain.WebhookEventTrackSkipped
+			}
+		default:
+			return
+		}
+		if g, err := srv.GuildSession(d.GuildID).State.Guild(d.GuildID); err == nil {
+			d.GuildName = g.Name
+		}
+
+		srv.UC.Webhook.Deliver(d)
+	}
+}
@@ -1,11 +1,12 @@
 package interaction
 
 import (
-	"log"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
 	"github.com/daystram/caroline/internal/interaction/caroline"
+	"github.com/daystram/caroline/internal/metrics"
 	"github.com/daystram/caroline/internal/server"
 	"github.com/daystram/caroline/internal/util"
 )
@@ -17,12 +18,26 @@ func interactions() []RegisterFunc {
 		caroline.RegisterNPComponent,
 		caroline.RegisterQueueComponent,
 		caroline.RegisterPlay,
+		caroline.RegisterSkip,
 		caroline.RegisterJump,
 		caroline.RegisterMove,
 		caroline.RegisterRemove,
 		caroline.RegisterReset,
+		caroline.RegisterPlaylist,
+		caroline.RegisterDuplicate,
+		caroline.RegisterMusicChannel,
+		caroline.RegisterSettings,
+		caroline.RegisterWebhook,
+		caroline.RegisterScrobble,
+		caroline.RegisterExport,
+		caroline.RegisterImport,
+		caroline.RegisterSummon,
+		caroline.RegisterNPHere,
 		caroline.RegisterBye,
 		caroline.RegisterStat,
+		caroline.RegisterVoiceState,
+		caroline.RegisterNPUpdater,
+		caroline.RegisterVoiceStatus,
 	}
 }
 
@@ -36,15 +51,17 @@ func RegisterAll(srv *server.Server) error {
 		}
 	}
 
-	srv.Session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		interaction := util.InteractionName(i)
-		h, ok := interactionHandlers[interaction]
+	srv.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		name := util.InteractionName(i)
+		h, ok := interactionHandlers[name]
 		if !ok {
-			log.Printf("[%s:@%s#%s] %s: unknown interaction: %s", i.GuildID, i.Member.User.Username, i.Member.User.Discriminator, i.Type, interaction)
+			srv.Log(i).Warn("unknown interaction")
 			return
 		}
-		log.Printf("[%s:@%s#%s] %s: %s", i.GuildID, i.Member.User.Username, i.Member.User.Discriminator, i.Type, interaction)
+		srv.Log(i).Info("interaction received")
+		start := time.Now()
 		h(s, i)
+		metrics.InteractionDuration.ObserveSince(start, name)
 	})
 
 	return nil
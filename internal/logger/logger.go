@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return ""
+	}
+}
+
+func ParseLevel(s string) (Level, error) {
+	for _, l := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level: %s", s)
+}
+
+type Format string
+
+const (
+	FormatLogfmt Format = "logfmt"
+	FormatJSON   Format = "json"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatLogfmt, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format: %s", s)
+	}
+}
+
+// Logger writes leveled records as key-value pairs. Loggers derived with With
+// share the same output.
+type Logger struct {
+	out    *output
+	fields []interface{}
+}
+
+type output struct {
+	w      io.Writer
+	level  Level
+	format Format
+	lock   sync.Mutex
+}
+
+func New(w io.Writer, level Level, format Format) *Logger {
+	return &Logger{
+		out: &output{
+			w:      w,
+			level:  level,
+			format: format,
+		},
+	}
+}
+
+// With returns a logger which adds the given key-value pairs to every record.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+	return &Logger{
+		out:    l.out,
+		fields: fields,
+	}
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.out.level
+}
+
+func (l *Logger) Debug(msg string, kv ...interface{}) {
+	l.Log(LevelDebug, msg, kv...)
+}
+
+func (l *Logger) Info(msg string, kv ...interface{}) {
+	l.Log(LevelInfo, msg, kv...)
+}
+
+func (l *Logger) Warn(msg string, kv ...interface{}) {
+	l.Log(LevelWarn, msg, kv...)
+}
+
+func (l *Logger) Error(msg string, kv ...interface{}) {
+	l.Log(LevelError, msg, kv...)
+}
+
+func (l *Logger) Log(level Level, msg string, kv ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	keys := []string{"time", "level", "msg"}
+	values := []interface{}{time.Now().UTC().Format(time.RFC3339Nano), level.String(), msg}
+	for _, pairs := range [][]interface{}{l.fields, kv} {
+		for i := 0; i < len(pairs); i += 2 {
+			key := fmt.Sprint(pairs[i])
+			var value interface{} = "(missing)"
+			if i+1 < len(pairs) {
+				value = pairs[i+1]
+			}
+			keys = append(keys, key)
+			values = append(values, redactValue(key, value))
+		}
+	}
+
+	var buf bytes.Buffer
+	switch l.out.format {
+	case FormatJSON:
+		encodeJSON(&buf, keys, values)
+	default:
+		encodeLogfmt(&buf, keys, values)
+	}
+	buf.WriteByte('\n')
+
+	l.out.lock.Lock()
+	defer l.out.lock.Unlock()
+	_, _ = l.out.w.Write(buf.Bytes())
+}
+
+// redactValue converts a value into its logged form, with sensitive content removed.
+func redactValue(key string, value interface{}) interface{} {
+	if isSensitiveKey(key) {
+		return redacted
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return RedactURLs(v)
+	case error:
+		return RedactURLs(v.Error())
+	case fmt.Stringer:
+		return RedactURLs(v.String())
+	default:
+		return RedactURLs(fmt.Sprint(v))
+	}
+}
+
+func encodeLogfmt(buf *bytes.Buffer, keys []string, values []interface{}) {
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		switch v := values[i].(type) {
+		case nil:
+			buf.WriteString("null")
+		case string:
+			if v == "" || strings.ContainsAny(v, " =\"\\") || strconv.Quote(v) != `"`+v+`"` {
+				buf.WriteString(strconv.Quote(v))
+			} else {
+				buf.WriteString(v)
+			}
+		default:
+			fmt.Fprint(buf, v)
+		}
+	}
+}
+
+func encodeJSON(buf *bytes.Buffer, keys []string, values []interface{}) {
+	marshal := func(v interface{}) []byte {
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			b.Reset()
+			_ = enc.Encode(fmt.Sprint(v))
+		}
+		return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+	}
+
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(marshal(key))
+		buf.WriteByte(':')
+		buf.Write(marshal(values[i]))
+	}
+	buf.WriteByte('}')
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+var (
+	urlPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+	// sensitiveKeys are field keys whose values are never logged.
+	sensitiveKeys = []string{"token", "secret", "password", "authorization"}
+
+	// safeQueryParams are URL query parameters kept as-is, everything else may carry
+	// signatures or session data, such as in stream URLs.
+	safeQueryParams = map[string]bool{
+		"v":     true,
+		"list":  true,
+		"index": true,
+		"t":     true,
+	}
+)
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURLs masks credentials and query parameter values of URLs found in s.
+func RedactURLs(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	return urlPattern.ReplaceAllStringFunc(s, RedactURL)
+}
+
+// RedactURL masks the credentials and query parameter values of a single URL.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k, vs := range q {
+			if safeQueryParams[k] {
+				continue
+			}
+			for i := range vs {
+				vs[i] = redacted
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
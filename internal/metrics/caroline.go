@@ -0,0 +1,18 @@
+package metrics
+
+var (
+	YouTubeDLPDuration = NewHistogram("caroline_ytdlp_duration_seconds", "Latency of yt-dlp calls.", DefaultBuckets, "op")
+	YouTubeDLPFailures = NewCounter("caroline_ytdlp_failures_total", "Failed yt-dlp calls.", "op")
+
+	SpotifyErrors = NewCounter("caroline_spotify_errors_total", "Failed Spotify API calls.", "op")
+
+	InteractionDuration = NewHistogram("caroline_interaction_duration_seconds", "Latency of handling interactions.", DefaultBuckets, "interaction")
+
+	NPMessageErrors = NewCounter("caroline_np_message_errors_total", "Failed now playing message updates.")
+
+	WebhookDeliveries = NewCounter("caroline_webhook_deliveries_total", "Webhook deliveries, by final result.", "result")
+
+	Scrobbles = NewCounter("caroline_scrobbles_total", "Scrobbling service submissions.", "service", "op", "result")
+
+	Events = NewCounter("caroline_events_total", "Player and queue events published.", "event")
+)
@@ -0,0 +1,249 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are latency buckets in seconds, covering fast Discord calls up to slow yt-dlp runs.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Registry holds metrics and writes them in the Prometheus text exposition format.
+type Registry struct {
+	metrics []metric
+	lock    sync.RWMutex
+}
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// Default is the registry the package level constructors register to.
+var Default = &Registry{}
+
+func (r *Registry) register(m metric) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *Registry) Write(w io.Writer) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range r.metrics {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_ = Default.Write(w)
+	})
+}
+
+// series holds the values of a metric, keyed by its label values.
+type series struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (s *series) key(labelValues []string) string {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: %s: expected %d label values, got %d", s.name, len(s.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func (s *series) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", s.name, s.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", s.name, s.kind)
+}
+
+func (s *series) writeSample(w io.Writer, suffix string, labelValues []string, extra []string, value float64) {
+	pairs := make([]string, 0, len(s.labels)+1)
+	for i, l := range s.labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", l, escapeLabel(labelValues[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extra[i], escapeLabel(extra[i+1])))
+	}
+	labels := ""
+	if len(pairs) > 0 {
+		labels = "{" + strings.Join(pairs, ",") + "}"
+	}
+	fmt.Fprintf(w, "%s%s%s %s\n", s.name, suffix, labels, formatValue(value))
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	series
+	values map[string]*counterValue
+	lock   sync.Mutex
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{
+		series: series{name: name, help: help, kind: "counter", labels: labels},
+		values: make(map[string]*counterValue),
+	}
+	Default.register(c)
+	return c
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(v float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cv, ok := c.values[key]
+	if !ok {
+		cv = &counterValue{labelValues: labelValues}
+		c.values[key] = cv
+	}
+	cv.value += v
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writeHeader(w)
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cv := c.values[key]
+		c.writeSample(w, "", cv.labelValues, nil, cv.value)
+	}
+}
+
+// Histogram counts observations into buckets.
+type Histogram struct {
+	series
+	buckets []float64
+	values  map[string]*histogramValue
+	lock    sync.Mutex
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		series:  series{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+	Default.register(h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			hv.counts[i]++
+		}
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// ObserveSince records the seconds elapsed since start.
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.writeHeader(w)
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		hv := h.values[key]
+		for i, b := range h.buckets {
+			h.writeSample(w, "_bucket", hv.labelValues, []string{"le", formatValue(b)}, float64(hv.counts[i]))
+		}
+		h.writeSample(w, "_bucket", hv.labelValues, []string{"le", "+Inf"}, float64(hv.count))
+		h.writeSample(w, "_sum", hv.labelValues, nil, hv.sum)
+		h.writeSample(w, "_count", hv.labelValues, nil, float64(hv.count))
+	}
+}
+
+// GaugeFunc is a gauge whose values are collected on every scrape.
+type GaugeFunc struct {
+	series
+	collect func(set func(v float64, labelValues ...string))
+}
+
+func NewGaugeFunc(name, help string, collect func(set func(v float64, labelValues ...string)), labels ...string) *GaugeFunc {
+	g := &GaugeFunc{
+		series:  series{name: name, help: help, kind: "gauge", labels: labels},
+		collect: collect,
+	}
+	Default.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.writeHeader(w)
+	g.collect(func(v float64, labelValues ...string) {
+		g.key(labelValues)
+		g.writeSample(w, "", labelValues, nil, v)
+	})
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/audio"
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
+)
+
+const (
+	audioWorkerCallTimeout = 2 * time.Second
+	audioWorkerJoinTimeout = 15 * time.Second
+	audioWorkerStopTimeout = 5 * time.Second
+	// audioWorkerPollInterval is how often the voice connection status is fetched from the workers
+	audioWorkerPollInterval = 2 * time.Second
+	voiceServerWaitTimeout  = 10 * time.Second
+)
+
+var errNoAudioWorkers = errors.New("no audio workers available")
+
+// NewAudioWorkerRepository delegates voice connections to audio workers listening on the given Unix sockets.
+// The bot only joins voice channels through the gateway, handing the resulting voice session to the least busy worker.
+func NewAudioWorkerRepository(addrs []string, log *logger.Logger) (domain.VoiceRepository, error) {
+	if len(addrs) == 0 {
+		return nil, errNoAudioWorkers
+	}
+
+	workers := make([]*audioWorker, 0, len(addrs))
+	for _, addr := range addrs {
+		workers = append(workers, &audioWorker{addr: addr})
+	}
+	r := &audioWorkerRepository{
+		workers:  workers,
+		log:      log.With("component", "audio"),
+		sessions: make(map[*discordgo.Session]bool),
+		voice:    make(map[string]string),
+		pending:  make(map[string]*pendingJoin),
+		conns:    make(map[string]*audioWorkerConnection),
+	}
+	go r.pollStatus()
+	return r, nil
+}
+
+type audioWorkerRepository struct {
+	workers []*audioWorker
+	log     *logger.Logger
+
+	// sessions are the gateway sessions whose voice events are handled
+	sessions map[*discordgo.Session]bool
+	// voice holds the bot's voice session ID per guild
+	voice   map[string]string
+	pending map[string]*pendingJoin
+	conns   map[string]*audioWorkerConnection
+	lock    sync.Mutex
+}
+
+var _ domain.VoiceRepository = (*audioWorkerRepository)(nil)
+
+// pendingJoin waits for both halves of a voice session, which the gateway sends as separate events.
+type pendingJoin struct {
+	channelID string
+	sessionID string
+	server    *discordgo.VoiceServerUpdate
+	done      chan struct{}
+}
+
+func (p *pendingJoin) check() {
+	if p.sessionID != "" && p.server != nil {
+		close(p.done)
+	}
+}
+
+func (r *audioWorkerRepository) Join(s *discordgo.Session, guildID, channelID string) (domain.VoiceConnection, error) {
+	r.lock.Lock()
+	if !r.sessions[s] {
+		r.sessions[s] = true
+		s.AddHandler(r.voiceStateUpdate)
+		s.AddHandler(r.voiceServerUpdate)
+	}
+	r.lock.Unlock()
+
+	w, err := r.pickWorker()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pendingJoin{channelID: channelID, done: make(chan struct{})}
+	r.lock.Lock()
+	r.pending[guildID] = p
+	r.lock.Unlock()
+	defer func() {
+		r.lock.Lock()
+		if r.pending[guildID] == p {
+			delete(r.pending, guildID)
+		}
+		r.lock.Unlock()
+	}()
+
+	err = s.ChannelVoiceJoinManual(guildID, channelID, false, true)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-p.done:
+	case <-time.After(voiceServerWaitTimeout):
+		_ = s.ChannelVoiceJoinManual(guildID, "", false, true)
+		return nil, errors.New("audio: timed out waiting for voice server")
+	}
+
+	err = w.call("Join", &audio.JoinArgs{
+		GuildID:   guildID,
+		UserID:    s.State.User.ID,
+		SessionID: p.sessionID,
+		Token:     p.server.Token,
+		Endpoint:  p.server.Endpoint,
+	}, &audio.Empty{}, audioWorkerJoinTimeout)
+	if err != nil {
+		_ = s.ChannelVoiceJoinManual(guildID, "", false, true)
+		return nil, fmt.Errorf("audio: worker %s: %w", w.addr, err)
+	}
+
+	// the worker only replies once the voice connection is ready
+	conn := &audioWorkerConnection{repo: r, session: s, worker: w, guildID: guildID, joined: time.Now(), ready: true}
+	r.lock.Lock()
+	r.conns[guildID] = conn
+	r.lock.Unlock()
+	r.log.Info("voice handed over", "guild", guildID, "worker", w.addr)
+	return conn, nil
+}
+
+// pollStatus keeps the readiness of every voice connection up to date, so checking it does not wait for a worker.
+// Connections of an unreachable worker are not ready.
+func (r *audioWorkerRepository) pollStatus() {
+	ticker := time.NewTicker(audioWorkerPollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.lock.Lock()
+		idle := len(r.conns) == 0
+		r.lock.Unlock()
+		if idle {
+			continue
+		}
+
+		for _, w := range r.workers {
+			polled := time.Now()
+			var stats audio.StatsReply
+			err := w.call("Stats", &audio.Empty{}, &stats, audioWorkerCallTimeout)
+			ready := make(map[string]bool, len(stats.Ready))
+			for _, guildID := range stats.Ready {
+				ready[guildID] = true
+			}
+			r.lock.Lock()
+			for guildID, conn := range r.conns {
+				// connections joined during the call may be missing from the reply
+				if conn.worker == w && conn.joined.Before(polled) {
+					conn.ready = err == nil && ready[guildID]
+				}
+			}
+			r.lock.Unlock()
+		}
+	}
+}
+
+// pickWorker returns the reachable worker holding the fewest voice connections.
+func (r *audioWorkerRepository) pickWorker() (*audioWorker, error) {
+	var (
+		best  *audioWorker
+		count int
+	)
+	for _, w := range r.workers {
+		var stats audio.StatsReply
+		err := w.call("Stats", &audio.Empty{}, &stats, audioWorkerCallTimeout)
+		if err != nil {
+			r.log.Warn("worker unavailable", "worker", w.addr, "err", err)
+			continue
+		}
+		if best == nil || stats.Connections < count {
+			best, count = w, stats.Connections
+		}
+	}
+	if best == nil {
+		return nil, errNoAudioWorkers
+	}
+	return best, nil
+}
+
+func (r *audioWorkerRepository) voiceStateUpdate(s *discordgo.Session, vsu *discordgo.VoiceStateUpdate) {
+	if vsu.UserID != s.State.User.ID {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.voice[vsu.GuildID] = vsu.SessionID
+	if p, ok := r.pending[vsu.GuildID]; ok && vsu.ChannelID == p.channelID && p.sessionID == "" {
+		p.sessionID = vsu.SessionID
+		p.check()
+	}
+}
+
+// voiceServerUpdate completes pending joins, or hands the new voice server over when Discord moves the guild.
+func (r *audioWorkerRepository) voiceServerUpdate(s *discordgo.Session, vsu *discordgo.VoiceServerUpdate) {
+	if vsu.Endpoint == "" {
+		// the voice server is gone, another update follows once allocated
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if p, ok := r.pending[vsu.GuildID]; ok {
+		if p.server == nil {
+			p.server = vsu
+			p.check()
+		}
+		return
+	}
+
+	conn, ok := r.conns[vsu.GuildID]
+	if !ok {
+		return
+	}
+	args := &audio.JoinArgs{
+		GuildID:   vsu.GuildID,
+		UserID:    s.State.User.ID,
+		SessionID: r.voice[vsu.GuildID],
+		Token:     vsu.Token,
+		Endpoint:  vsu.Endpoint,
+	}
+	go func() {
+		err := conn.worker.call("Join", args, &audio.Empty{}, audioWorkerJoinTimeout)
+		if err != nil {
+			r.log.Warn("voice server handover failed", "guild", args.GuildID, "worker", conn.worker.addr, "err", err)
+		}
+	}()
+}
+
+// audioWorker is a connection to a single worker, redialled after the worker restarts.
+type audioWorker struct {
+	addr   string
+	client *rpc.Client
+	lock   sync.Mutex
+}
+
+func (w *audioWorker) dial() (*rpc.Client, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.client != nil {
+		return w.client, nil
+	}
+	client, err := rpc.Dial("unix", w.addr)
+	if err != nil {
+		return nil, err
+	}
+	w.client = client
+	return client, nil
+}
+
+// checkError drops the client once the worker is gone, e.g. after it crashed, errors returned by the worker aside.
+func (w *audioWorker) checkError(client *rpc.Client, err error) {
+	var serverErr rpc.ServerError
+	if err == nil || errors.As(err, &serverErr) {
+		return
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.client == client {
+		_ = w.client.Close()
+		w.client = nil
+	}
+}
+
+// call makes an RPC, waiting at most timeout for its reply.
+func (w *audioWorker) call(method string, args, reply interface{}, timeout time.Duration) error {
+	call, client, err := w.start(method, args, reply)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-call.Done:
+	case <-timer.C:
+		return fmt.Errorf("%s: timed out", method)
+	}
+	w.checkError(client, call.Error)
+	return call.Error
+}
+
+func (w *audioWorker) start(method string, args, reply interface{}) (*rpc.Call, *rpc.Client, error) {
+	client, err := w.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.Go(audio.ServiceName+"."+method, args, reply, make(chan *rpc.Call, 1)), client, nil
+}
+
+type audioWorkerConnection struct {
+	repo    *audioWorkerRepository
+	session *discordgo.Session
+	worker  *audioWorker
+	guildID string
+
+	// ready is last reported by the worker after joined, guarded by the repository lock
+	joined time.Time
+	ready  bool
+}
+
+var _ domain.VoiceConnection = (*audioWorkerConnection)(nil)
+
+func (c *audioWorkerConnection) Ready() bool {
+	c.repo.lock.Lock()
+	defer c.repo.lock.Unlock()
+	return c.ready
+}
+
+func (c *audioWorkerConnection) Play(source string, offset time.Duration, volume float64, stop <-chan bool) {
+	log := c.repo.log.With("guild", c.guildID, "worker", c.worker.addr)
+	call, client, err := c.worker.start("Play", &audio.PlayArgs{
+		GuildID: c.guildID,
+		Source:  source,
+		Offset:  offset,
+		Volume:  volume,
+	}, &audio.Empty{})
+	if err != nil {
+		log.Warn("play failed", "err", err)
+		return
+	}
+
+	select {
+	case <-call.Done:
+	case <-stop:
+		err := c.worker.call("Stop", &audio.GuildArgs{GuildID: c.guildID}, &audio.Empty{}, audioWorkerCallTimeout)
+		if err != nil {
+			log.Warn("stop failed", "err", err)
+		}
+		select {
+		case <-call.Done:
+		case <-time.After(audioWorkerStopTimeout):
+			log.Warn("stop timed out")
+			return
+		}
+	}
+	c.worker.checkError(client, call.Error)
+	if call.Error != nil {
+		log.Warn("play failed", "err", call.Error)
+	}
+}
+
+func (c *audioWorkerConnection) ChangeChannel(channelID string) error {
+	// the voice session is kept, a new voice server is handed over if Discord allocates one
+	return c.session.ChannelVoiceJoinManual(c.guildID, channelID, false, true)
+}
+
+func (c *audioWorkerConnection) Disconnect() error {
+	c.repo.lock.Lock()
+	if c.repo.conns[c.guildID] == c {
+		delete(c.repo.conns, c.guildID)
+	}
+	c.repo.lock.Unlock()
+
+	err := c.worker.call("Leave", &audio.GuildArgs{GuildID: c.guildID}, &audio.Empty{}, audioWorkerCallTimeout)
+	if err != nil {
+		c.repo.log.Warn("leave failed", "guild", c.guildID, "worker", c.worker.addr, "err", err)
+	}
+	return c.session.ChannelVoiceJoinManual(c.guildID, "", false, true)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	lastFMAPIURL  = "https://ws.audioscrobbler.com/2.0/"
+	lastFMAuthURL = "https://www.last.fm/api/auth/?api_key=%s&token=%s"
+
+	// https://www.last.fm/api/errorcodes
+	lastFMErrorAuthFailed        = 4
+	lastFMErrorInvalidSessionKey = 9
+	lastFMErrorTokenUnauthorized = 14
+
+	scrobbleTimeout = 10 * time.Second
+)
+
+func NewLastFMClient(apiKey, apiSecret string) (domain.ScrobbleClient, error) {
+	return &lastFMClient{
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		client:    &http.Client{Timeout: scrobbleTimeout},
+	}, nil
+}
+
+type lastFMClient struct {
+	apiKey    string
+	apiSecret string
+
+	client *http.Client
+}
+
+var _ domain.ScrobbleClient = (*lastFMClient)(nil)
+
+type lastFMError struct {
+	Code    int    `json:"error"`
+	Message string `json:"message"`
+}
+
+func (c *lastFMClient) AuthToken() (string, string, error) {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	err := c.call(http.MethodGet, "auth.getToken", url.Values{}, &resp)
+	if err != nil {
+		return "", "", err
+	}
+
+	return resp.Token, fmt.Sprintf(lastFMAuthURL, url.QueryEscape(c.apiKey), url.QueryEscape(resp.Token)), nil
+}
+
+func (c *lastFMClient) Session(token string) (string, string, error) {
+	var resp struct {
+		Session struct {
+			Name string `json:"name"`
+			Key  string `json:"key"`
+		} `json:"session"`
+	}
+	err := c.call(http.MethodGet, "auth.getSession", url.Values{"token": {token}}, &resp)
+	if err != nil {
+		return "", "", err
+	}
+
+	return resp.Session.Name, resp.Session.Key, nil
+}
+
+func (c *lastFMClient) NowPlaying(key string, s *domain.Scrobble) error {
+	params := url.Values{
+		"sk":     {key},
+		"artist": {s.Artist},
+		"track":  {s.Track},
+	}
+	if s.Duration > 0 {
+		params.Set("duration", strconv.Itoa(int(s.Duration/time.Second)))
+	}
+
+	return c.call(http.MethodPost, "track.updateNowPlaying", params, nil)
+}
+
+func (c *lastFMClient) Scrobble(key string, s *domain.Scrobble) error {
+	params := url.Values{
+		"sk":        {key},
+		"artist":    {s.Artist},
+		"track":     {s.Track},
+		"timestamp": {strconv.FormatInt(s.StartedAt.Unix(), 10)},
+	}
+	if s.Duration > 0 {
+		params.Set("duration", strconv.Itoa(int(s.Duration/time.Second)))
+	}
+
+	return c.call(http.MethodPost, "track.scrobble", params, nil)
+}
+
+// call invokes a signed API method, decoding the response into out when not nil.
+func (c *lastFMClient) call(httpMethod, method string, params url.Values, out interface{}) error {
+	params.Set("method", method)
+	params.Set("api_key", c.apiKey)
+	params.Set("api_sig", c.sign(params))
+	params.Set("format", "json")
+
+	var req *http.Request
+	var err error
+	if httpMethod == http.MethodGet {
+		req, err = http.NewRequest(http.MethodGet, lastFMAPIURL+"?"+params.Encode(), nil)
+	} else {
+		req, err = http.NewRequest(http.MethodPost, lastFMAPIURL, strings.NewReader(params.Encode()))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", scrobbleUserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body json.RawMessage
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return fmt.Errorf("lastfm: %s: unexpected response: %s", method, resp.Status)
+	}
+	apiErr := lastFMError{}
+	_ = json.Unmarshal(body, &apiErr)
+	switch apiErr.Code {
+	case 0:
+	case lastFMErrorAuthFailed, lastFMErrorInvalidSessionKey, lastFMErrorTokenUnauthorized:
+		return fmt.Errorf("%w: lastfm: %s: %s", domain.ErrScrobbleUnauthorized, method, apiErr.Message)
+	default:
+		return fmt.Errorf("lastfm: %s: error %d: %s", method, apiErr.Code, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// sign computes the api_sig of the parameters, see https://www.last.fm/api/authspec.
+func (c *lastFMClient) sign(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	builder := strings.Builder{}
+	for _, k := range keys {
+		builder.WriteString(k)
+		builder.WriteString(params.Get(k))
+	}
+	builder.WriteString(c.apiSecret)
+	sum := md5.Sum([]byte(builder.String()))
+
+	return hex.EncodeToString(sum[:])
+}
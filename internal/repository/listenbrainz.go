@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	listenBrainzAPIURL = "https://api.listenbrainz.org/1/"
+
+	scrobbleUserAgent = "caroline (+https://github.com/daystram/caroline)"
+)
+
+func NewListenBrainzClient() (domain.ScrobbleClient, error) {
+	return &listenBrainzClient{
+		client: &http.Client{Timeout: scrobbleTimeout},
+	}, nil
+}
+
+type listenBrainzClient struct {
+	client *http.Client
+}
+
+var _ domain.ScrobbleClient = (*listenBrainzClient)(nil)
+
+type listenBrainzSubmission struct {
+	ListenType string               `json:"listen_type"`
+	Payload    []listenBrainzListen `json:"payload"`
+}
+
+type listenBrainzListen struct {
+	ListenedAt    int64                     `json:"listened_at,omitempty"`
+	TrackMetadata listenBrainzTrackMetadata `json:"track_metadata"`
+}
+
+type listenBrainzTrackMetadata struct {
+	ArtistName     string                     `json:"artist_name"`
+	TrackName      string                     `json:"track_name"`
+	AdditionalInfo listenBrainzAdditionalInfo `json:"additional_info"`
+}
+
+type listenBrainzAdditionalInfo struct {
+	DurationMs       int64  `json:"duration_ms,omitempty"`
+	OriginURL        string `json:"origin_url,omitempty"`
+	MediaPlayer      string `json:"media_player"`
+	SubmissionClient string `json:"submission_client"`
+}
+
+// AuthToken is not supported, ListenBrainz accounts are linked with the user token from their settings page.
+func (c *listenBrainzClient) AuthToken() (string, string, error) {
+	return "", "", errors.New("listenbrainz: accounts are linked with user tokens")
+}
+
+func (c *listenBrainzClient) Session(token string) (string, string, error) {
+	var resp struct {
+		Valid    bool   `json:"valid"`
+		UserName string `json:"user_name"`
+	}
+	err := c.call(http.MethodGet, "validate-token", token, nil, &resp)
+	if err != nil {
+		return "", "", err
+	}
+	if !resp.Valid {
+		return "", "", fmt.Errorf("%w: listenbrainz: invalid token", domain.ErrScrobbleUnauthorized)
+	}
+
+	return resp.UserName, token, nil
+}
+
+func (c *listenBrainzClient) NowPlaying(key string, s *domain.Scrobble) error {
+	return c.call(http.MethodPost, "submit-listens", key, &listenBrainzSubmission{
+		ListenType: "playing_now",
+		Payload:    []listenBrainzListen{{TrackMetadata: newListenBrainzTrackMetadata(s)}},
+	}, nil)
+}
+
+func (c *listenBrainzClient) Scrobble(key string, s *domain.Scrobble) error {
+	return c.call(http.MethodPost, "submit-listens", key, &listenBrainzSubmission{
+		ListenType: "single",
+		Payload: []listenBrainzListen{{
+			ListenedAt:    s.StartedAt.Unix(),
+			TrackMetadata: newListenBrainzTrackMetadata(s),
+		}},
+	}, nil)
+}
+
+func (c *listenBrainzClient) call(method, endpoint, token string, in, out interface{}) error {
+	var body bytes.Buffer
+	if in != nil {
+		err := json.NewEncoder(&body).Encode(in)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, listenBrainzAPIURL+endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", scrobbleUserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: listenbrainz: %s: %s", domain.ErrScrobbleUnauthorized, endpoint, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("listenbrainz: %s: %s: %s", endpoint, resp.Status, apiErr.Error)
+	}
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func newListenBrainzTrackMetadata(s *domain.Scrobble) listenBrainzTrackMetadata {
+	return listenBrainzTrackMetadata{
+		ArtistName: s.Artist,
+		TrackName:  s.Track,
+		AdditionalInfo: listenBrainzAdditionalInfo{
+			DurationMs:       s.Duration.Milliseconds(),
+			OriginURL:        s.URL,
+			MediaPlayer:      "caroline",
+			SubmissionClient: "caroline",
+		},
+	}
+}
@@ -6,9 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"os/exec"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,39 +18,65 @@ import (
 	"golang.org/x/oauth2/clientcredentials"
 
 	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/metrics"
+)
+
+var (
+	youtubeTitleNoiseRegex = regexp.MustCompile(`(?i)\s*[(\[][^)\]]*\b(official|lyrics?|audio|video|visuali[sz]er|mv|hd|4k)\b[^)\]]*[)\]]`)
 )
 
 const (
-	youtubeDLPBinary  = "yt-dlp"
-	youtubeDLPRetries = 3
-	youtubeURLPattern = "https://youtu.be/"
+	youtubeDLPRetries    = 3
+	youtubeURLPattern    = "https://youtu.be/"
+	youtubeMixURLPattern = "https://www.youtube.com/watch?v=%s&list=RD%s"
 )
 
-func NewMusicRepository(spClientID, spClientSecret string) (domain.MusicRepository, error) {
-	spCtx := context.Background()
-	config := &clientcredentials.Config{
-		ClientID:     spClientID,
-		ClientSecret: spClientSecret,
-		TokenURL:     spotifyauth.TokenURL,
+func NewMusicRepository(spClientID, spClientSecret, ytDLPPath string, ytDLPTimeout time.Duration, log *logger.Logger) (domain.MusicRepository, error) {
+	r := &musicRepository{
+		spCtx:        context.Background(),
+		ytDLPPath:    ytDLPPath,
+		ytDLPTimeout: ytDLPTimeout,
+		log:          log.With("component", "music"),
+	}
+
+	// spotify is optional, its links are rejected without credentials
+	if spClientID != "" && spClientSecret != "" {
+		config := &clientcredentials.Config{
+			ClientID:     spClientID,
+			ClientSecret: spClientSecret,
+			TokenURL:     spotifyauth.TokenURL,
+		}
+		r.spAPI = spotify.New(config.Client(r.spCtx))
 	}
-	spAPI := spotify.New(config.Client(spCtx))
 
-	return &musicRepository{
-		spAPI: spAPI,
-		spCtx: spCtx,
-	}, nil
+	return r, nil
 }
 
 type musicRepository struct {
 	spAPI *spotify.Client
 	spCtx context.Context
+
+	ytDLPPath    string
+	ytDLPTimeout time.Duration
+
+	log *logger.Logger
 }
 
 var _ domain.MusicRepository = (*musicRepository)(nil)
 
+func (r *musicRepository) SpotifyEnabled() bool {
+	return r.spAPI != nil
+}
+
 func (r *musicRepository) GetSpotifyPlaylist(id string) (*spotify.FullPlaylist, []spotify.PlaylistTrack, error) {
+	if r.spAPI == nil {
+		return nil, nil, domain.ErrSpotifyDisabled
+	}
+
 	p, err := r.spAPI.GetPlaylist(r.spCtx, spotify.ID(id))
 	if err != nil {
+		metrics.SpotifyErrors.Inc("playlist")
 		return nil, nil, err
 	}
 
@@ -61,6 +88,7 @@ func (r *musicRepository) GetSpotifyPlaylist(id string) (*spotify.FullPlaylist,
 			break
 		}
 		if err != nil {
+			metrics.SpotifyErrors.Inc("playlist")
 			return nil, nil, err
 		}
 	}
@@ -68,15 +96,90 @@ func (r *musicRepository) GetSpotifyPlaylist(id string) (*spotify.FullPlaylist,
 	return p, t, nil
 }
 
+func (r *musicRepository) GetRelated(seeds []*domain.Music, limit int) ([]*domain.Music, error) {
+	musics := make([]*domain.Music, 0, limit)
+
+	// prefer spotify recommendations when any seed came from spotify
+	spIDs := make([]spotify.ID, 0, spotify.MaxNumberOfSeeds)
+	for i := len(seeds) - 1; i >= 0 && len(spIDs) < spotify.MaxNumberOfSeeds; i-- {
+		if seeds[i].SpotifyTrackID != "" {
+			spIDs = append(spIDs, spotify.ID(seeds[i].SpotifyTrackID))
+		}
+	}
+	if len(spIDs) > 0 && r.spAPI != nil {
+		rec, err := r.spAPI.GetRecommendations(r.spCtx, spotify.Seeds{Tracks: spIDs}, nil, spotify.Limit(limit))
+		if err != nil {
+			metrics.SpotifyErrors.Inc("recommendations")
+			r.log.Warn("spotify: recommendations failed", "err", err)
+		} else {
+			for _, t := range rec.Tracks {
+				query := t.Name
+				if len(t.Artists) > 0 {
+					query = fmt.Sprintf("%s - %s", t.Name, t.Artists[0].Name)
+				}
+				musics = append(musics, &domain.Music{
+					Query:          query,
+					Source:         domain.MusicSourceAutoplay,
+					SpotifyTrackID: t.ID.String(),
+				})
+			}
+			if len(musics) > 0 {
+				return musics, nil
+			}
+		}
+	}
+
+	// otherwise follow the youtube mix of the most recent track
+	var videoID string
+	for i := len(seeds) - 1; i >= 0 && videoID == ""; i-- {
+		videoID = youtubeVideoID(seeds[i])
+	}
+	if videoID == "" {
+		return nil, domain.ErrMusicNotFound
+	}
+	entries, err := r.execYouTubeDLPMix(videoID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.ID == "" || e.ID == videoID || len(musics) == limit {
+			continue
+		}
+		musics = append(musics, &domain.Music{
+			Query:          e.Title,
+			Source:         domain.MusicSourceAutoplay,
+			YouTubeVideoID: e.ID,
+		})
+	}
+	if len(musics) == 0 {
+		return nil, domain.ErrMusicNotFound
+	}
+
+	return musics, nil
+}
+
+// setSpotifyTrack sets the search query and metadata of m from its Spotify track, which may have no artists.
+func setSpotifyTrack(m *domain.Music, track *spotify.FullTrack) {
+	m.Query = track.Name
+	if len(track.Artists) > 0 {
+		m.Query = fmt.Sprintf("%s - %s", track.Name, track.Artists[0].Name)
+		m.Artist, m.Track = track.Artists[0].Name, track.Name
+	}
+}
+
 func (r *musicRepository) Load(m *domain.Music) error {
 	var videoID string
 	switch m.Source {
 	case domain.MusicSourceSpotifyPlaylist, domain.MusicSourceSpotifyTrack:
+		if r.spAPI == nil {
+			return domain.ErrSpotifyDisabled
+		}
 		track, err := r.spAPI.GetTrack(r.spCtx, spotify.ID(m.SpotifyTrackID), spotify.Limit(1))
 		if err != nil {
+			metrics.SpotifyErrors.Inc("track")
 			return err
 		}
-		m.Query = fmt.Sprintf("%s - %s", track.Name, track.Artists[0].Name)
+		setSpotifyTrack(m, track)
 		// continue searching below
 
 	case domain.MusicSourceYouTubeVideo:
@@ -84,14 +187,25 @@ func (r *musicRepository) Load(m *domain.Music) error {
 
 	case domain.MusicSourceSearch:
 		// continue searching below
+
+	case domain.MusicSourceAutoplay:
+		if m.SpotifyTrackID != "" && r.spAPI != nil {
+			track, err := r.spAPI.GetTrack(r.spCtx, spotify.ID(m.SpotifyTrackID), spotify.Limit(1))
+			if err != nil {
+				metrics.SpotifyErrors.Inc("track")
+				return err
+			}
+			setSpotifyTrack(m, track)
+		}
+		videoID = m.YouTubeVideoID
 	}
 
 	var err error
 	var resp *YouTubeDLResponse
 	if videoID == "" {
-		resp, err = execYouTubeDLP(fmt.Sprintf("ytsearch1:'%s'", m.Query))
+		resp, err = r.execYouTubeDLP(fmt.Sprintf("ytsearch1:'%s'", m.Query))
 	} else {
-		resp, err = execYouTubeDLP(videoID)
+		resp, err = r.execYouTubeDLP(videoID)
 	}
 	if err != nil {
 		return err
@@ -106,13 +220,16 @@ func (r *musicRepository) Load(m *domain.Music) error {
 		m.Thumbnail = resp.Thumbnails[len(resp.Thumbnails)-1].URL
 	}
 	m.Duration = time.Duration(resp.Duration) * time.Second
+	if m.Artist == "" {
+		m.Artist, m.Track = youtubeArtistTrack(resp)
+	}
 	m.Loaded = true
 
 	return nil
 }
 
 func (r *musicRepository) GetStreamURL(music *domain.Music) (string, error) {
-	resp, err := execYouTubeDLP(music.URL)
+	resp, err := r.execYouTubeDLP(music.URL)
 	if err != nil {
 		return "", err
 	}
@@ -129,6 +246,9 @@ func (r *musicRepository) GetStreamURL(music *domain.Music) (string, error) {
 type YouTubeDLResponse struct {
 	ID         string               `json:"id"`
 	Title      string               `json:"title"`
+	Artist     string               `json:"artist"`
+	Track      string               `json:"track"`
+	Channel    string               `json:"channel"`
 	Duration   int                  `json:"duration"`
 	Formats    []YouTubeDLFormat    `json:"formats"`
 	Thumbnails []YouTubeDLThumbnail `json:"thumbnails"`
@@ -147,20 +267,23 @@ type YouTubeDLThumbnail struct {
 	Width  int    `json:"width"`
 }
 
-func execYouTubeDLP(arg ...string) (*YouTubeDLResponse, error) {
+func (r *musicRepository) execYouTubeDLP(arg ...string) (*YouTubeDLResponse, error) {
 	// TODO: proper retry/backoff
 	exec := func() (*YouTubeDLResponse, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), r.ytDLPTimeout)
 		defer cancel()
-		cmd := exec.CommandContext(ctx, youtubeDLPBinary, append(arg, "--dump-json", "--force-ipv4")...)
-		log.Printf("%s: exec: \"%s\"\n", youtubeDLPBinary, cmd.String())
+		cmd := exec.CommandContext(ctx, r.ytDLPPath, append(arg, "--dump-json", "--force-ipv4")...)
+		r.log.Debug("yt-dlp: exec", "cmd", cmd.String())
 
 		var stdout, stderr bytes.Buffer
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
+		start := time.Now()
 		err := cmd.Run()
+		metrics.YouTubeDLPDuration.ObserveSince(start, "dump")
 		if err != nil {
+			metrics.YouTubeDLPFailures.Inc("dump")
 			return nil, fmt.Errorf("%w: %s", err, strings.ReplaceAll(stderr.String(), "\n", "\\n"))
 		}
 
@@ -180,13 +303,79 @@ func execYouTubeDLP(arg ...string) (*YouTubeDLResponse, error) {
 		if err == nil {
 			return resp, nil
 		}
-		log.Printf("%s: attempt %d failed: %s\n", youtubeDLPBinary, i, err)
+		r.log.Warn("yt-dlp: attempt failed", "attempt", i+1, "err", err)
 		time.Sleep(500 * time.Millisecond)
 	}
 
 	return nil, fmt.Errorf("%w: %s", domain.ErrMusicNotFound, err)
 }
 
+func (r *musicRepository) execYouTubeDLPMix(videoID string, limit int) ([]YouTubeDLResponse, error) {
+	// mixes are resolved as playlists, allow them more time
+	ctx, cancel := context.WithTimeout(context.Background(), 2*r.ytDLPTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, r.ytDLPPath, fmt.Sprintf(youtubeMixURLPattern, videoID, videoID),
+		"--flat-playlist", "--dump-json", "--playlist-end", strconv.Itoa(limit), "--force-ipv4")
+	r.log.Debug("yt-dlp: exec", "cmd", cmd.String())
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	metrics.YouTubeDLPDuration.ObserveSince(start, "mix")
+	if err != nil {
+		metrics.YouTubeDLPFailures.Inc("mix")
+		return nil, fmt.Errorf("%w: %s", err, strings.ReplaceAll(stderr.String(), "\n", "\\n"))
+	}
+
+	// flat playlists are dumped as one JSON object per line
+	entries := make([]YouTubeDLResponse, 0, limit)
+	for _, line := range bytes.Split(stdout.Bytes(), []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		e := YouTubeDLResponse{}
+		err = json.Unmarshal(line, &e)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// youtubeArtistTrack guesses the artist and track name of a video, preferring the music metadata
+// YouTube provides, then "Artist - Track" titles, then auto-generated "Artist - Topic" channels.
+func youtubeArtistTrack(resp *YouTubeDLResponse) (string, string) {
+	if resp.Artist != "" && resp.Track != "" {
+		return resp.Artist, resp.Track
+	}
+	if parts := strings.SplitN(resp.Title, " - ", 2); len(parts) == 2 {
+		artist := strings.TrimSpace(parts[0])
+		track := strings.TrimSpace(youtubeTitleNoiseRegex.ReplaceAllString(parts[1], ""))
+		if artist != "" && track != "" {
+			return artist, track
+		}
+	}
+	if artist := strings.TrimSuffix(resp.Channel, " - Topic"); artist != resp.Channel {
+		return artist, resp.Title
+	}
+	return "", ""
+}
+
+func youtubeVideoID(m *domain.Music) string {
+	if m.YouTubeVideoID != "" {
+		return m.YouTubeVideoID
+	}
+	if m.Loaded && strings.HasPrefix(m.URL, youtubeURLPattern) {
+		return strings.TrimPrefix(m.URL, youtubeURLPattern)
+	}
+	return ""
+}
+
 func filterFormats(formats []YouTubeDLFormat, ext, acodec string) []YouTubeDLFormat {
 	result := make([]YouTubeDLFormat, 0)
 	for _, f := range formats {
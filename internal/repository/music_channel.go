@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	musicChannelDirName  = "music_channels"
+	musicChannelFileName = "music_channels.json"
+)
+
+func NewMusicChannelRepository(dataDir string) (domain.MusicChannelRepository, error) {
+	store, err := newKeyedStore(dataDir, musicChannelDirName, musicChannelFileName, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &musicChannelRepository{
+		store: store,
+	}, nil
+}
+
+type musicChannelRepository struct {
+	store *keyedStore
+	lock  sync.RWMutex
+}
+
+var _ domain.MusicChannelRepository = (*musicChannelRepository)(nil)
+
+func (r *musicChannelRepository) Get(guildID string) (*domain.MusicChannel, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var mc *domain.MusicChannel
+	err := r.store.read(guildID, &mc)
+	if err != nil {
+		return nil, err
+	}
+	if mc == nil {
+		return nil, domain.ErrMusicChannelNotFound
+	}
+
+	return mc, nil
+}
+
+func (r *musicChannelRepository) Save(mc *domain.MusicChannel) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.store.write(mc.GuildID, mc)
+}
+
+func (r *musicChannelRepository) Delete(guildID string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var mc *domain.MusicChannel
+	err := r.store.read(guildID, &mc)
+	if err != nil {
+		return err
+	}
+	if mc == nil {
+		return domain.ErrMusicChannelNotFound
+	}
+
+	return r.store.remove(guildID)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	playlistDirName  = "playlists"
+	playlistFileName = "playlists.json"
+)
+
+func NewPlaylistRepository(dataDir string) (domain.PlaylistRepository, error) {
+	store, err := newKeyedStore(dataDir, playlistDirName, playlistFileName, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &playlistRepository{
+		store: store,
+	}, nil
+}
+
+type playlistRepository struct {
+	// store holds the playlists of each guild, by name
+	store *keyedStore
+	lock  sync.RWMutex
+}
+
+var _ domain.PlaylistRepository = (*playlistRepository)(nil)
+
+func (r *playlistRepository) Save(playlist *domain.Playlist) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	pls := make(map[string]*domain.Playlist)
+	err := r.store.read(playlist.GuildID, &pls)
+	if err != nil {
+		return err
+	}
+	pls[playlist.Name] = playlist
+
+	return r.store.write(playlist.GuildID, pls)
+}
+
+func (r *playlistRepository) Get(guildID, name string) (*domain.Playlist, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	pls := make(map[string]*domain.Playlist)
+	err := r.store.read(guildID, &pls)
+	if err != nil {
+		return nil, err
+	}
+	pl, ok := pls[name]
+	if !ok {
+		return nil, domain.ErrPlaylistNotFound
+	}
+
+	return pl, nil
+}
+
+func (r *playlistRepository) List(guildID string) ([]*domain.Playlist, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	pls := make(map[string]*domain.Playlist)
+	err := r.store.read(guildID, &pls)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*domain.Playlist, 0, len(pls))
+	for _, pl := range pls {
+		list = append(list, pl)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+func (r *playlistRepository) Delete(guildID, name string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	pls := make(map[string]*domain.Playlist)
+	err := r.store.read(guildID, &pls)
+	if err != nil {
+		return err
+	}
+	if _, ok := pls[name]; !ok {
+		return domain.ErrPlaylistNotFound
+	}
+	delete(pls, name)
+	if len(pls) == 0 {
+		return r.store.remove(guildID)
+	}
+
+	return r.store.write(guildID, pls)
+}
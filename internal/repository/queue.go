@@ -80,6 +80,7 @@ func (r *queueRepository) Jump(guildID string, pos int) error {
 	}
 
 	q.CurrentPos = pos
+	q.Resume()
 
 	return nil
 }
@@ -110,6 +111,9 @@ func (r *queueRepository) Move(guildID string, from, to int) error {
 		} else if from < q.CurrentPos && q.CurrentPos <= to {
 			q.CurrentPos--
 		}
+		if from < q.EndPos && q.EndPos <= to {
+			q.EndPos--
+		}
 	}
 	if to < from {
 		temp := q.ActiveTracks[from]
@@ -122,6 +126,9 @@ func (r *queueRepository) Move(guildID string, from, to int) error {
 		} else if to <= q.CurrentPos && q.CurrentPos < from {
 			q.CurrentPos++
 		}
+		if to < q.EndPos && q.EndPos <= from {
+			q.EndPos++
+		}
 	}
 
 	return nil
@@ -144,6 +151,9 @@ func (r *queueRepository) Remove(guildID string, pos int) error {
 	if pos <= q.CurrentPos {
 		q.CurrentPos--
 	}
+	if pos < q.EndPos {
+		q.EndPos--
+	}
 
 	return nil
 }
@@ -204,6 +214,34 @@ func (r *queueRepository) SetShuffleMode(guildID string, mode domain.ShuffleMode
 	return nil
 }
 
+func (r *queueRepository) SetFairMode(guildID string, mode domain.FairMode) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	q, ok := r.queues[guildID]
+	if !ok {
+		return domain.ErrQueueNotFound
+	}
+
+	q.Fair = mode
+
+	return nil
+}
+
+func (r *queueRepository) SetAutoplayMode(guildID string, mode domain.AutoplayMode) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	q, ok := r.queues[guildID]
+	if !ok {
+		return domain.ErrQueueNotFound
+	}
+
+	q.Autoplay = mode
+
+	return nil
+}
+
 func (r *queueRepository) Clear(guildID string) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -214,10 +252,13 @@ func (r *queueRepository) Clear(guildID string) error {
 	}
 
 	q.CurrentPos = -1
+	q.EndPos = 0
 	q.ActiveTracks = make([]*domain.Music, 0)
 	q.Loop = domain.LoopModeOff
 	q.Shuffle = domain.ShuffleModeOff
 	q.OriginalTracks = make([]*domain.Music, 0)
+	q.Fair = domain.FairModeOff
+	q.Autoplay = domain.AutoplayModeOff
 
 	return nil
 }
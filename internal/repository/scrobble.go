@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	scrobbleDirName  = "scrobble_accounts"
+	scrobbleFileName = "scrobble_accounts.json"
+)
+
+func NewScrobbleRepository(dataDir string) (domain.ScrobbleRepository, error) {
+	// session keys and user tokens are stored in plain text, keep them private
+	store, err := newKeyedStore(dataDir, scrobbleDirName, scrobbleFileName, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &scrobbleRepository{
+		store: store,
+	}, nil
+}
+
+type scrobbleRepository struct {
+	// store holds the accounts of each user, shared by every shard as users are not tied to a guild
+	store *keyedStore
+	lock  sync.RWMutex
+}
+
+var _ domain.ScrobbleRepository = (*scrobbleRepository)(nil)
+
+func (r *scrobbleRepository) Save(account *domain.ScrobbleAccount) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	accounts := make(map[domain.ScrobbleService]*domain.ScrobbleAccount)
+	err := r.store.read(account.UserID, &accounts)
+	if err != nil {
+		return err
+	}
+	accounts[account.Service] = account
+
+	return r.store.write(account.UserID, accounts)
+}
+
+func (r *scrobbleRepository) List(userID string) ([]*domain.ScrobbleAccount, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	accounts := make(map[domain.ScrobbleService]*domain.ScrobbleAccount)
+	err := r.store.read(userID, &accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*domain.ScrobbleAccount, 0, len(accounts))
+	for _, a := range accounts {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Service < list[j].Service
+	})
+
+	return list, nil
+}
+
+func (r *scrobbleRepository) Delete(userID string, service domain.ScrobbleService) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	accounts := make(map[domain.ScrobbleService]*domain.ScrobbleAccount)
+	err := r.store.read(userID, &accounts)
+	if err != nil {
+		return err
+	}
+	if _, ok := accounts[service]; !ok {
+		return domain.ErrScrobbleNotFound
+	}
+	delete(accounts, service)
+	if len(accounts) == 0 {
+		return r.store.remove(userID)
+	}
+
+	return r.store.write(userID, accounts)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	settingsDirName  = "settings"
+	settingsFileName = "settings.json"
+)
+
+func NewSettingsRepository(dataDir string, defaults domain.GuildSettings) (domain.SettingsRepository, error) {
+	store, err := newKeyedStore(dataDir, settingsDirName, settingsFileName, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settingsRepository{
+		store:    store,
+		defaults: defaults,
+	}, nil
+}
+
+type settingsRepository struct {
+	// store holds the overrides of each guild
+	store    *keyedStore
+	defaults domain.GuildSettings
+	lock     sync.RWMutex
+}
+
+var _ domain.SettingsRepository = (*settingsRepository)(nil)
+
+func (r *settingsRepository) Get(guildID string) (*domain.GuildSettings, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	overrides := make(map[domain.SettingKey]string)
+	err := r.store.read(guildID, &overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	g := r.defaults
+	g.GuildID = guildID
+	for key, value := range overrides {
+		// skip overrides that no longer parse instead of failing the whole guild
+		_ = g.Apply(key, value)
+	}
+
+	return &g, nil
+}
+
+func (r *settingsRepository) Set(guildID string, key domain.SettingKey, value string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	overrides := make(map[domain.SettingKey]string)
+	err := r.store.read(guildID, &overrides)
+	if err != nil {
+		return err
+	}
+	overrides[key] = value
+
+	return r.store.write(guildID, overrides)
+}
+
+func (r *settingsRepository) Reset(guildID string, key domain.SettingKey) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	overrides := make(map[domain.SettingKey]string)
+	err := r.store.read(guildID, &overrides)
+	if err != nil {
+		return err
+	}
+	delete(overrides, key)
+	if len(overrides) == 0 {
+		return r.store.remove(guildID)
+	}
+
+	return r.store.write(guildID, overrides)
+}
+
+func (r *settingsRepository) ResetAll(guildID string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.store.remove(guildID)
+}
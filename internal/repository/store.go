@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keyedStore keeps the records of each guild or user in their own JSON file, so that processes sharing the
+// data directory, such as one per shard, never overwrite each other's records whichever shard owns a guild.
+// Files are read on every access to pick up the changes made by other processes.
+type keyedStore struct {
+	dir  string
+	perm os.FileMode
+}
+
+// newKeyedStore opens the store in the name directory under dataDir,
+// first splitting legacyFileName, which held every key's records in earlier versions.
+func newKeyedStore(dataDir, name, legacyFileName string, perm os.FileMode) (*keyedStore, error) {
+	s := &keyedStore{
+		dir:  filepath.Join(dataDir, name),
+		perm: perm,
+	}
+	err := os.MkdirAll(s.dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.migrate(filepath.Join(dataDir, legacyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("migrate %s: %w", legacyFileName, err)
+	}
+
+	return s, nil
+}
+
+func (s *keyedStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(s.dir, key+".json"), nil
+}
+
+// read decodes the records of key into v, leaving v untouched if there are none.
+func (s *keyedStore) read(key string, v interface{}) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// write replaces the records of key with v.
+func (s *keyedStore) write(key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.writeRaw(key, b)
+}
+
+func (s *keyedStore) writeRaw(key string, b []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first to avoid leaving a corrupted file behind,
+	// named uniquely as another process may be writing the same key
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Chmod(s.perm)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// remove deletes the records of key, if any.
+func (s *keyedStore) remove(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// migrate splits the legacy file into a file per key, then sets it aside. Keys already having their own file
+// are left alone, so that processes starting at the same time cannot clobber records updated since.
+func (s *keyedStore) migrate(legacyPath string) error {
+	b, err := os.ReadFile(legacyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	records := make(map[string]json.RawMessage)
+	err = json.Unmarshal(b, &records)
+	if err != nil {
+		return err
+	}
+	for key, raw := range records {
+		path, err := s.path(key)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stat(path)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		err = s.writeRaw(key, raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.Rename(legacyPath, legacyPath+".migrated")
+	if errors.Is(err, os.ErrNotExist) {
+		// set aside by another process
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyedStorePath(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "81384788765712384"},
+		{key: "", wantErr: true},
+		{key: ".", wantErr: true},
+		{key: "..", wantErr: true},
+		{key: "../settings", wantErr: true},
+		{key: `a\b`, wantErr: true},
+	}
+
+	s := &keyedStore{dir: t.TempDir()}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			_, err := s.path(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("path() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyedStoreMigrate(t *testing.T) {
+	dataDir := t.TempDir()
+	legacy := `{"1": {"volume": "50"}, "2": {"volume": "80"}}`
+	if err := os.WriteFile(filepath.Join(dataDir, "settings.json"), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// written by a process that migrated first and has been updated since
+	if err := os.MkdirAll(filepath.Join(dataDir, "settings"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "settings", "2.json"), []byte(`{"volume": "120"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newKeyedStore(dataDir, "settings", "settings.json", 0o644)
+	if err != nil {
+		t.Fatalf("newKeyedStore() error = %v", err)
+	}
+
+	want := map[string]string{"1": "50", "2": "120", "3": ""}
+	for key, volume := range want {
+		v := make(map[string]string)
+		if err := s.read(key, &v); err != nil {
+			t.Fatalf("read(%s) error = %v", key, err)
+		}
+		if v["volume"] != volume {
+			t.Errorf("read(%s) volume = %q, want %q", key, v["volume"], volume)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "settings.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("legacy file not set aside, stat error = %v", err)
+	}
+
+	// opening again, as another process would, keeps the migrated records
+	if err := s.write("1", map[string]string{"volume": "60"}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	s, err = newKeyedStore(dataDir, "settings", "settings.json", 0o644)
+	if err != nil {
+		t.Fatalf("newKeyedStore() error = %v", err)
+	}
+	v := make(map[string]string)
+	if err := s.read("1", &v); err != nil || v["volume"] != "60" {
+		t.Errorf("read(1) = %v, %v, want volume 60", v, err)
+	}
+
+	if err := s.remove("1"); err != nil {
+		t.Fatalf("remove() error = %v", err)
+	}
+	if err := s.remove("1"); err != nil {
+		t.Errorf("remove() of missing key error = %v", err)
+	}
+	v = make(map[string]string)
+	if err := s.read("1", &v); err != nil || len(v) != 0 {
+		t.Errorf("read(1) after remove = %v, %v, want none", v, err)
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/util"
+)
+
+// NewVoiceRepository plays audio in the bot process, through discordgo's voice connections.
+func NewVoiceRepository() (domain.VoiceRepository, error) {
+	return &voiceRepository{}, nil
+}
+
+type voiceRepository struct{}
+
+var _ domain.VoiceRepository = (*voiceRepository)(nil)
+
+func (r *voiceRepository) Join(s *discordgo.Session, guildID, channelID string) (domain.VoiceConnection, error) {
+	conn, err := s.ChannelVoiceJoin(guildID, channelID, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return &voiceConnection{conn: conn}, nil
+}
+
+type voiceConnection struct {
+	conn *discordgo.VoiceConnection
+}
+
+var _ domain.VoiceConnection = (*voiceConnection)(nil)
+
+func (c *voiceConnection) Ready() bool {
+	c.conn.RLock()
+	defer c.conn.RUnlock()
+	return c.conn.Ready
+}
+
+func (c *voiceConnection) Play(source string, offset time.Duration, volume float64, stop <-chan bool) {
+	util.PlayAudioFile(c.conn, source, offset, volume, stop)
+}
+
+func (c *voiceConnection) ChangeChannel(channelID string) error {
+	return c.conn.ChangeChannel(channelID, false, true)
+}
+
+func (c *voiceConnection) Disconnect() error {
+	return c.conn.Disconnect()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	webhookDirName  = "webhooks"
+	webhookFileName = "webhooks.json"
+)
+
+func NewWebhookRepository(dataDir string) (domain.WebhookRepository, error) {
+	// signing secrets are stored in plain text, keep them private
+	store, err := newKeyedStore(dataDir, webhookDirName, webhookFileName, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookRepository{
+		store: store,
+	}, nil
+}
+
+type webhookRepository struct {
+	// store holds the webhooks of each guild, by ID
+	store *keyedStore
+	lock  sync.RWMutex
+}
+
+var _ domain.WebhookRepository = (*webhookRepository)(nil)
+
+func (r *webhookRepository) Save(webhook *domain.Webhook) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	whs := make(map[string]*domain.Webhook)
+	err := r.store.read(webhook.GuildID, &whs)
+	if err != nil {
+		return err
+	}
+	whs[webhook.ID] = webhook
+
+	return r.store.write(webhook.GuildID, whs)
+}
+
+func (r *webhookRepository) List(guildID string) ([]*domain.Webhook, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	whs := make(map[string]*domain.Webhook)
+	err := r.store.read(guildID, &whs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*domain.Webhook, 0, len(whs))
+	for _, wh := range whs {
+		list = append(list, wh)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list, nil
+}
+
+func (r *webhookRepository) Delete(guildID, id string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	whs := make(map[string]*domain.Webhook)
+	err := r.store.read(guildID, &whs)
+	if err != nil {
+		return err
+	}
+	if _, ok := whs[id]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	delete(whs, id)
+	if len(whs) == 0 {
+		return r.store.remove(guildID)
+	}
+
+	return r.store.write(guildID, whs)
+}
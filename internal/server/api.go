@@ -0,0 +1,452 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	apiPrefix       = "/api/guilds/"
+	apiMaxBodyBytes = 1 << 16
+)
+
+var (
+	errAPIGuildNotFound   = errors.New("guild not found")
+	errAPIRouteNotFound   = errors.New("route not found")
+	errAPINowPlaying      = errors.New("cannot change currently playing track")
+	errAPIInvalidBody     = errors.New("invalid request body")
+	errAPIInvalidMode     = errors.New("invalid mode")
+	errAPIMissingQuery    = errors.New("query is required")
+	errAPIUnauthorized    = errors.New("unauthorized")
+	errAPIEnqueueRejected = errors.New("could not add to queue")
+)
+
+type apiMusic struct {
+	Position int     `json:"position"`
+	Title    string  `json:"title"`
+	Query    string  `json:"query"`
+	URL      string  `json:"url,omitempty"`
+	Duration float64 `json:"duration,omitempty"`
+	Source   string  `json:"source"`
+	QueuedBy string  `json:"queued_by"`
+	QueuedAt string  `json:"queued_at"`
+}
+
+type apiPlayer struct {
+	GuildID        string    `json:"guild_id"`
+	Status         string    `json:"status"`
+	VoiceChannelID string    `json:"voice_channel_id,omitempty"`
+	NPChannelID    string    `json:"np_channel_id,omitempty"`
+	NowPlaying     *apiMusic `json:"now_playing"`
+	StartedAt      string    `json:"started_at,omitempty"`
+	Elapsed        float64   `json:"elapsed,omitempty"`
+	Loop           string    `json:"loop"`
+	Shuffle        string    `json:"shuffle"`
+	Autoplay       string    `json:"autoplay"`
+}
+
+type apiQueue struct {
+	GuildID    string      `json:"guild_id"`
+	CurrentPos int         `json:"current_position"`
+	Tracks     []*apiMusic `json:"tracks"`
+}
+
+func newAPIMusic(m *domain.Music, pos int) *apiMusic {
+	if m == nil {
+		return nil
+	}
+	title := m.Title
+	if title == "" {
+		title = m.Query
+	}
+	return &apiMusic{
+		Position: pos + 1,
+		Title:    title,
+		Query:    m.Query,
+		URL:      m.URL,
+		Duration: m.Duration.Seconds(),
+		Source:   m.Source.String(),
+		QueuedBy: m.QueuedByID,
+		QueuedAt: m.QueuedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// apiHandler serves the remote control API, all routes are scoped to a guild:
+//
+//	GET    /api/guilds/{guildID}/player
+//	GET    /api/guilds/{guildID}/queue
+//	POST   /api/guilds/{guildID}/queue        {"query": "...", "position": 1}
+//	DELETE /api/guilds/{guildID}/queue/{pos}
+//	POST   /api/guilds/{guildID}/skip
+//	POST   /api/guilds/{guildID}/jump         {"position": "+1"}
+//	POST   /api/guilds/{guildID}/move         {"from": 3, "to": 2}
+//	PUT    /api/guilds/{guildID}/loop         {"mode": "all"}
+//	PUT    /api/guilds/{guildID}/shuffle      {"mode": "on"}
+func (s *Server) apiHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(auth[len("Bearer "):]), []byte(token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, errAPIUnauthorized)
+			return
+		}
+
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, apiPrefix), "/"), "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			writeAPIError(w, http.StatusNotFound, errAPIRouteNotFound)
+			return
+		}
+		guildID := parts[0]
+		if _, err := s.GuildSession(guildID).State.Guild(guildID); err != nil {
+			writeAPIError(w, http.StatusNotFound, errAPIGuildNotFound)
+			return
+		}
+		log := s.Logger.With("type", "api", "guild", guildID, "method", r.Method, "path", r.URL.Path)
+		log.Info("api request received")
+
+		r.Body = http.MaxBytesReader(w, r.Body, apiMaxBodyBytes)
+		var status int
+		var resp interface{}
+		var err error
+		switch route := r.Method + " " + strings.Join(parts[1:], "/"); {
+		case route == "GET player":
+			status, resp, err = s.apiGetPlayer(guildID)
+		case route == "GET queue":
+			status, resp, err = s.apiGetQueue(guildID)
+		case route == "POST queue":
+			status, resp, err = s.apiEnqueue(guildID, r)
+		case r.Method == http.MethodDelete && len(parts) == 3 && parts[1] == "queue":
+			status, resp, err = s.apiRemove(guildID, parts[2])
+		case route == "POST skip":
+			status, resp, err = s.apiSkip(guildID)
+		case route == "POST jump":
+			status, resp, err = s.apiJump(guildID, r)
+		case route == "POST move":
+			status, resp, err = s.apiMove(guildID, r)
+		case route == "PUT loop":
+			status, resp, err = s.apiSetLoop(guildID, r)
+		case route == "PUT shuffle":
+			status, resp, err = s.apiSetShuffle(guildID, r)
+		default:
+			status, err = http.StatusNotFound, errAPIRouteNotFound
+		}
+		if err != nil {
+			if status >= http.StatusInternalServerError {
+				log.Error("api request failed", "err", err)
+				err = errors.New(http.StatusText(status))
+			}
+			writeAPIError(w, status, err)
+			return
+		}
+		writeAPIResponse(w, status, resp)
+	})
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIResponse(w, status, map[string]string{"error": err.Error()})
+}
+
+func decodeAPIBody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %s", errAPIInvalidBody, err)
+	}
+	return nil
+}
+
+// apiActivePlayer returns the guild's player and queue, failing when the bot is not in a voice channel.
+func (s *Server) apiActivePlayer(guildID string) (*domain.Player, *domain.Queue, int, error) {
+	p, err := s.UC.Player.Get(guildID)
+	if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+	q, err := s.UC.Queue.Get(guildID)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+	if !util.IsPlayerReady(p) {
+		return nil, nil, http.StatusConflict, domain.ErrNotPlaying
+	}
+	return p, q, http.StatusOK, nil
+}
+
+// newAPIPlayer describes the player state, p may be nil when the bot is not in a voice channel.
+func newAPIPlayer(guildID string, p *domain.Player, q *domain.Queue) *apiPlayer {
+	resp := &apiPlayer{
+		GuildID:  guildID,
+		Status:   domain.PlayerStatusUninitialized.String(),
+		Loop:     q.Loop.String(),
+		Shuffle:  q.Shuffle.String(),
+		Autoplay: q.Autoplay.String(),
+	}
+	if util.IsPlayerReady(p) {
+		resp.Status = p.Status.String()
+		resp.VoiceChannelID = p.VoiceChannel.ID
+		resp.NPChannelID = p.NPChannel.ID
+		resp.NowPlaying = newAPIMusic(q.NowPlaying(), q.CurrentPos)
+		if p.Status == domain.PlayerStatusPlaying && !p.CurrentStartTime.IsZero() {
+			resp.StartedAt = p.CurrentStartTime.UTC().Format(time.RFC3339Nano)
+			resp.Elapsed = time.Since(p.CurrentStartTime).Seconds()
+		}
+	}
+	return resp
+}
+
+func newAPIQueue(q *domain.Queue) *apiQueue {
+	resp := &apiQueue{
+		GuildID:    q.GuildID,
+		CurrentPos: q.CurrentPos + 1,
+		Tracks:     make([]*apiMusic, 0, len(q.ActiveTracks)),
+	}
+	for pos, m := range q.ActiveTracks {
+		resp.Tracks = append(resp.Tracks, newAPIMusic(m, pos))
+	}
+	return resp
+}
+
+func (s *Server) apiGetPlayer(guildID string) (int, interface{}, error) {
+	q, err := s.UC.Queue.Get(guildID)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	p, err := s.UC.Player.Get(guildID)
+	if err != nil && !errors.Is(err, domain.ErrNotPlaying) {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, newAPIPlayer(guildID, p, q), nil
+}
+
+func (s *Server) apiGetQueue(guildID string) (int, interface{}, error) {
+	q, err := s.UC.Queue.Get(guildID)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, newAPIQueue(q), nil
+}
+
+func (s *Server) apiEnqueue(guildID string, r *http.Request) (int, interface{}, error) {
+	var req struct {
+		Query    string `json:"query"`
+		Position int    `json:"position"`
+	}
+	if err := decodeAPIBody(r, &req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return http.StatusBadRequest, nil, errAPIMissingQuery
+	}
+
+	p, q, status, err := s.apiActivePlayer(guildID)
+	if err != nil {
+		return status, nil, err
+	}
+	pos := -1
+	if req.Position != 0 {
+		if req.Position < 1 || req.Position > len(q.ActiveTracks)+1 {
+			return http.StatusBadRequest, nil, domain.ErrQueueOutOfBounds
+		}
+		pos = req.Position - 1
+	}
+
+	// tracks queued through the API are attributed to the bot
+	_, musics, err := s.UC.Music.Parse(req.Query, s.Session.State.User)
+	if errors.Is(err, domain.ErrSpotifyDisabled) {
+		return http.StatusBadRequest, nil, err
+	}
+	if err != nil {
+		return http.StatusNotFound, nil, domain.ErrMusicNotFound
+	}
+
+	res, err := s.UC.Queue.EnqueueAll(q, musics, pos)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if len(res.Positions) == 0 {
+		switch {
+		case res.RejectErr != nil:
+			return http.StatusConflict, nil, res.RejectErr
+		case res.Duplicates > 0:
+			return http.StatusConflict, nil, domain.ErrDuplicate
+		default:
+			return http.StatusConflict, nil, errAPIEnqueueRejected
+		}
+	}
+	resp := make([]*apiMusic, 0, len(res.Positions))
+	for _, idx := range res.Positions {
+		resp = append(resp, newAPIMusic(q.ActiveTracks[idx], idx))
+	}
+
+	if p.Status != domain.PlayerStatusPlaying {
+		err = s.UC.Queue.Jump(q, res.StartPos)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+	}
+	err = s.UC.Player.Play(p)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusCreated, map[string]interface{}{"added": resp}, nil
+}
+
+func (s *Server) apiRemove(guildID, posRaw string) (int, interface{}, error) {
+	_, q, status, err := s.apiActivePlayer(guildID)
+	if err != nil {
+		return status, nil, err
+	}
+	pos, err := util.ParseRelativePosOption(q, posRaw)
+	if err != nil {
+		return http.StatusBadRequest, nil, domain.ErrQueueOutOfBounds
+	}
+	if pos == q.CurrentPos {
+		return http.StatusConflict, nil, errAPINowPlaying
+	}
+
+	removed := newAPIMusic(q.ActiveTracks[pos], pos)
+	err = s.UC.Queue.Remove(q, pos)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, map[string]interface{}{"removed": removed}, nil
+}
+
+func (s *Server) apiSkip(guildID string) (int, interface{}, error) {
+	p, q, status, err := s.apiActivePlayer(guildID)
+	if err != nil {
+		return status, nil, err
+	}
+	if q.NowPlaying() == nil {
+		return http.StatusConflict, nil, domain.ErrNotPlaying
+	}
+
+	err = s.UC.Queue.Jump(q, (q.CurrentPos+1)%len(q.ActiveTracks))
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	err = s.UC.Player.Skip(p)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, map[string]interface{}{"now_playing": newAPIMusic(q.NowPlaying(), q.CurrentPos)}, nil
+}
+
+func (s *Server) apiJump(guildID string, r *http.Request) (int, interface{}, error) {
+	var req struct {
+		Position string `json:"position"`
+	}
+	if err := decodeAPIBody(r, &req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	p, q, status, err := s.apiActivePlayer(guildID)
+	if err != nil {
+		return status, nil, err
+	}
+	pos, err := util.ParseRelativePosOption(q, req.Position)
+	if err != nil {
+		return http.StatusBadRequest, nil, domain.ErrQueueOutOfBounds
+	}
+
+	err = s.UC.Queue.Jump(q, pos)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	err = s.UC.Player.Skip(p)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, map[string]interface{}{"now_playing": newAPIMusic(q.NowPlaying(), q.CurrentPos)}, nil
+}
+
+func (s *Server) apiMove(guildID string, r *http.Request) (int, interface{}, error) {
+	var req struct {
+		From int `json:"from"`
+		To   int `json:"to"`
+	}
+	if err := decodeAPIBody(r, &req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	_, q, status, err := s.apiActivePlayer(guildID)
+	if err != nil {
+		return status, nil, err
+	}
+	from, to := req.From-1, req.To-1
+	if from < 0 || from > len(q.ActiveTracks)-1 || to < 0 || to > len(q.ActiveTracks)-1 {
+		return http.StatusBadRequest, nil, domain.ErrQueueOutOfBounds
+	}
+	if from == q.CurrentPos || to == q.CurrentPos {
+		return http.StatusConflict, nil, errAPINowPlaying
+	}
+
+	err = s.UC.Queue.Move(q, from, to)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, map[string]interface{}{"moved": newAPIMusic(q.ActiveTracks[to], to)}, nil
+}
+
+func (s *Server) apiSetLoop(guildID string, r *http.Request) (int, interface{}, error) {
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if err := decodeAPIBody(r, &req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	mode, err := domain.ParseLoopMode(req.Mode)
+	if err != nil {
+		return http.StatusBadRequest, nil, errAPIInvalidMode
+	}
+
+	_, q, status, err := s.apiActivePlayer(guildID)
+	if err != nil {
+		return status, nil, err
+	}
+	err = s.UC.Queue.SetLoopMode(q, mode)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, map[string]string{"loop": q.Loop.String()}, nil
+}
+
+func (s *Server) apiSetShuffle(guildID string, r *http.Request) (int, interface{}, error) {
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if err := decodeAPIBody(r, &req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	var mode domain.ShuffleMode
+	switch req.Mode {
+	case domain.ShuffleModeOff.String():
+		mode = domain.ShuffleModeOff
+	case domain.ShuffleModeOn.String():
+		mode = domain.ShuffleModeOn
+	default:
+		return http.StatusBadRequest, nil, errAPIInvalidMode
+	}
+
+	_, q, status, err := s.apiActivePlayer(guildID)
+	if err != nil {
+		return status, nil, err
+	}
+	err = s.UC.Queue.SetShuffleMode(q, mode)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, map[string]string{"shuffle": q.Shuffle.String()}, nil
+}
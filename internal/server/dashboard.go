@@ -0,0 +1,247 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	dashboardPrefix       = "/dashboard/"
+	dashboardWriteTimeout = 10 * time.Second
+	dashboardPongTimeout  = 60 * time.Second
+	dashboardPingInterval = dashboardPongTimeout * 9 / 10
+)
+
+//go:embed web
+var dashboardAssets embed.FS
+
+type dashboardGuild struct {
+	GuildID string     `json:"guild_id"`
+	Name    string     `json:"name"`
+	Icon    string     `json:"icon,omitempty"`
+	Player  *apiPlayer `json:"player"`
+	Queue   *apiQueue  `json:"queue"`
+}
+
+// dashboardHub pushes the state of every active player to connected dashboards.
+// Snapshots are rebuilt whenever a player or queue event is published, and only
+// broadcast when they change.
+type dashboardHub struct {
+	srv      *Server
+	upgrader websocket.Upgrader
+
+	clients map[*dashboardClient]bool
+	last    []byte
+	lock    sync.Mutex
+
+	refreshes chan struct{}
+	done      chan struct{}
+}
+
+type dashboardClient struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+func newDashboardHub(srv *Server) *dashboardHub {
+	return &dashboardHub{
+		srv:       srv,
+		clients:   make(map[*dashboardClient]bool),
+		refreshes: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+func dashboardHandler() http.Handler {
+	assets, _ := fs.Sub(dashboardAssets, "web")
+	return http.StripPrefix(dashboardPrefix, http.FileServer(http.FS(assets)))
+}
+
+func (h *dashboardHub) run() {
+	unsubscribe := h.srv.Events.Subscribe("dashboard", func(domain.Event) { h.refresh() })
+	defer unsubscribe()
+	for {
+		select {
+		case <-h.done:
+			h.lock.Lock()
+			for c := range h.clients {
+				delete(h.clients, c)
+				close(c.send)
+			}
+			h.lock.Unlock()
+			return
+		case <-h.refreshes:
+		}
+
+		h.lock.Lock()
+		if len(h.clients) == 0 {
+			h.last = nil
+			h.lock.Unlock()
+			continue
+		}
+		h.lock.Unlock()
+
+		msg, err := json.Marshal(map[string]interface{}{"guilds": h.snapshot()})
+		if err != nil {
+			h.srv.Logger.Error("dashboard: failed to build snapshot", "err", err)
+			continue
+		}
+		h.broadcast(msg)
+	}
+}
+
+func (h *dashboardHub) stop() {
+	close(h.done)
+}
+
+// refresh requests an immediate snapshot.
+func (h *dashboardHub) refresh() {
+	select {
+	case h.refreshes <- struct{}{}:
+	default:
+	}
+}
+
+func (h *dashboardHub) snapshot() []*dashboardGuild {
+	guilds := make([]*dashboardGuild, 0)
+	for _, p := range h.srv.UC.Player.GetAll() {
+		if !util.IsPlayerReady(p) {
+			continue
+		}
+		q, err := h.srv.UC.Queue.Get(p.GuildID)
+		if err != nil {
+			continue
+		}
+		g := &dashboardGuild{
+			GuildID: p.GuildID,
+			Player:  newAPIPlayer(p.GuildID, p, q),
+			Queue:   newAPIQueue(q),
+		}
+		// progress is derived from started_at by the client
+		g.Player.Elapsed = 0
+		if guild, err := h.srv.GuildSession(p.GuildID).State.Guild(p.GuildID); err == nil {
+			g.Name = guild.Name
+			g.Icon = guild.IconURL("64")
+		}
+		guilds = append(guilds, g)
+	}
+	sort.Slice(guilds, func(i, j int) bool {
+		return guilds[i].Name < guilds[j].Name
+	})
+	return guilds
+}
+
+func (h *dashboardHub) broadcast(msg []byte) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if bytes.Equal(msg, h.last) {
+		return
+	}
+	h.last = msg
+	for c := range h.clients {
+		select {
+		case c.send <- msg:
+		default:
+			// too slow to keep up, let it reconnect
+			delete(h.clients, c)
+			close(c.send)
+		}
+	}
+}
+
+func (h *dashboardHub) register(c *dashboardClient) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.clients[c] = true
+	if h.last != nil {
+		c.send <- h.last
+	}
+	h.refresh()
+}
+
+func (h *dashboardHub) unregister(c *dashboardClient) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.clients[c] {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+// serveWS upgrades dashboard connections. Browsers cannot set headers on WebSocket
+// requests, so the API token is passed as a query parameter instead.
+func (h *dashboardHub) serveWS(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, errAPIUnauthorized)
+			return
+		}
+		conn, err := h.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		c := &dashboardClient{
+			conn: conn,
+			send: make(chan []byte, 8),
+		}
+		h.register(c)
+		go h.writeLoop(c)
+		h.readLoop(c)
+	})
+}
+
+func (h *dashboardHub) readLoop(c *dashboardClient) {
+	defer func() {
+		h.unregister(c)
+		_ = c.conn.Close()
+	}()
+	_ = c.conn.SetReadDeadline(time.Now().Add(dashboardPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(dashboardPongTimeout))
+	})
+	// dashboards only listen, changes are made through the API
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (h *dashboardHub) writeLoop(c *dashboardClient) {
+	ticker := time.NewTicker(dashboardPingInterval)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+	for {
+		select {
+		case msg, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(dashboardWriteTimeout))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(dashboardWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daystram/caroline/internal/metrics"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const httpShutdownTimeout = 5 * time.Second
+
+func (s *Server) startHTTP(addr, apiToken string) {
+	metrics.NewGaugeFunc("caroline_discord_session_ready", "Whether the Discord gateway session of a shard is ready.", func(set func(float64, ...string)) {
+		for _, shard := range s.Shards {
+			v := 0.0
+			if shard.DataReady {
+				v = 1
+			}
+			set(v, strconv.Itoa(shard.ShardID))
+		}
+	}, "shard")
+	metrics.NewGaugeFunc("caroline_active_speakers", "Number of players connected to a voice channel.", func(set func(float64, ...string)) {
+		n := 0
+		for _, p := range s.UC.Player.GetAll() {
+			if util.IsPlayerReady(p) {
+				n++
+			}
+		}
+		set(float64(n))
+	})
+	metrics.NewGaugeFunc("caroline_queue_length", "Number of tracks in the queue of active players.", func(set func(float64, ...string)) {
+		for _, p := range s.UC.Player.GetAll() {
+			if !util.IsPlayerReady(p) {
+				continue
+			}
+			q, err := s.UC.Queue.Get(p.GuildID)
+			if err != nil {
+				continue
+			}
+			set(float64(len(q.ActiveTracks)), p.GuildID)
+		}
+	}, "guild")
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/healthz", s.healthz)
+	mux.HandleFunc("/readyz", s.readyz)
+	if apiToken != "" {
+		s.dashboard = newDashboardHub(s)
+		go s.dashboard.run()
+		mux.Handle(apiPrefix, s.apiHandler(apiToken))
+		mux.Handle(dashboardPrefix, dashboardHandler())
+		mux.Handle(dashboardPrefix+"ws", s.dashboard.serveWS(apiToken))
+	}
+
+	s.http = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		err := s.http.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Logger.Error("http: server failed", "err", err)
+		}
+	}()
+	s.Logger.Info("http: server started", "addr", addr)
+}
+
+func (s *Server) stopHTTP() error {
+	if s.http == nil {
+		return nil
+	}
+	if s.dashboard != nil {
+		s.dashboard.stop()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	return s.http.Shutdown(ctx)
+}
+
+// healthz reports whether the Discord gateway session of every shard is up.
+func (s *Server) healthz(w http.ResponseWriter, _ *http.Request) {
+	if !s.Ready() {
+		http.Error(w, "discord: session not ready", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "discord: ok")
+}
+
+// readyz additionally reports whether every active player has a working voice connection.
+func (s *Server) readyz(w http.ResponseWriter, _ *http.Request) {
+	if !s.Ready() {
+		http.Error(w, "discord: session not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	total, broken := 0, 0
+	for _, p := range s.UC.Player.GetAll() {
+		if !util.IsPlayerReady(p) {
+			continue
+		}
+		total++
+		if p.Conn == nil || !p.Conn.Ready() || p.ReconnectAttempt > 0 {
+			broken++
+		}
+	}
+	if broken > 0 {
+		http.Error(w, fmt.Sprintf("discord: ok\nvoice: %d of %d connections not ready", broken, total), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "discord: ok\nvoice: ok (%d %s)\n", total, util.Plural("connection", total))
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/util"
+)
+
+const (
+	presenceDefault = "github.com/daystram/caroline"
+	// presence updates share the gateway rate limit, later changes are coalesced into one update
+	presenceMinInterval = 15 * time.Second
+	presenceMaxLength   = 128
+)
+
+type presence struct {
+	activity discordgo.ActivityType
+	name     string
+}
+
+// startPresence keeps the bot's presence in sync with the active players.
+func (s *Server) startPresence() {
+	updates := make(chan struct{}, 1)
+	update := func() {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+
+	s.Events.Subscribe("presence", func(e domain.Event) {
+		switch e.(type) {
+		case domain.TrackStarted, domain.PlayerStateChanged:
+			update()
+		}
+	})
+
+	var (
+		last presence
+		lock sync.Mutex
+	)
+	// presence is reset when a shard's gateway session is re-identified
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Ready) {
+		lock.Lock()
+		last = presence{}
+		lock.Unlock()
+		update()
+	})
+
+	go func() {
+		update()
+		for range updates {
+			next := s.currentPresence()
+			lock.Lock()
+			unchanged := next == last
+			lock.Unlock()
+			if unchanged {
+				continue
+			}
+
+			// presence is per shard, shown alike on all of them
+			failed := false
+			for _, shard := range s.Shards {
+				var err error
+				if next.activity == discordgo.ActivityTypeListening {
+					err = shard.UpdateListeningStatus(next.name)
+				} else {
+					err = shard.UpdateGameStatus(0, next.name)
+				}
+				if err != nil {
+					s.Logger.Warn("presence: update failed", "shard", shard.ShardID, "err", err)
+					failed = true
+				}
+			}
+			if failed {
+				continue
+			}
+			lock.Lock()
+			last = next
+			lock.Unlock()
+			time.Sleep(presenceMinInterval)
+		}
+	}()
+}
+
+// currentPresence shows the track when exactly one guild is playing, or the number of guilds otherwise.
+func (s *Server) currentPresence() presence {
+	var playing []*domain.Player
+	for _, p := range s.UC.Player.GetAll() {
+		if p.Status == domain.PlayerStatusPlaying {
+			playing = append(playing, p)
+		}
+	}
+
+	switch len(playing) {
+	case 0:
+		return presence{activity: discordgo.ActivityTypeGame, name: presenceDefault}
+	case 1:
+		q, err := s.UC.Queue.Get(playing[0].GuildID)
+		if err != nil {
+			break
+		}
+		music := q.NowPlaying()
+		if music == nil {
+			break
+		}
+		title := music.Query
+		if music.Loaded {
+			title = music.Title
+		}
+		return presence{activity: discordgo.ActivityTypeListening, name: util.Truncate(title, presenceMaxLength)}
+	}
+
+	return presence{
+		activity: discordgo.ActivityTypeGame,
+		name:     fmt.Sprintf("in %d %s", len(playing), util.Plural("server", len(playing))),
+	}
+}
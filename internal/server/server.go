@@ -2,51 +2,156 @@ package server
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 
 	"github.com/daystram/caroline/internal/config"
 	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/event"
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/metrics"
+	"github.com/daystram/caroline/internal/util"
 )
 
 type Server struct {
-	Session *discordgo.Session
-	UC      useCases
+	// Session is the first shard's session, used for REST calls not tied to a guild, e.g. registering commands
+	Session    *discordgo.Session
+	Shards     []*discordgo.Session
+	ShardCount int
+
+	UC     useCases
+	Events domain.EventBus
+	Logger *logger.Logger
 
 	StartTime    time.Time
 	DebugGuildID string
+	// MessageContentIntent is set when the bot can read message content, e.g. queries sent to music channels
+	MessageContentIntent bool
+
+	http      *http.Server
+	dashboard *dashboardHub
 }
 
 type useCases struct {
-	Music  domain.MusicUseCase
-	Player domain.PlayerUseCase
-	Queue  domain.QueueUseCase
+	Music        domain.MusicUseCase
+	Player       domain.PlayerUseCase
+	Queue        domain.QueueUseCase
+	Playlist     domain.PlaylistUseCase
+	Permission   domain.PermissionUseCase
+	MusicChannel domain.MusicChannelUseCase
+	Settings     domain.SettingsUseCase
+	Webhook      domain.WebhookUseCase
+	Scrobble     domain.ScrobbleUseCase
 }
 
-func Start(cfg *config.Config, musicUC domain.MusicUseCase, playerUC domain.PlayerUseCase, queueUC domain.QueueUseCase) (*Server, error) {
-	s, err := discordgo.New(fmt.Sprintf("Bot %s", cfg.BotToken))
-	if err != nil {
-		return nil, err
+func Start(cfg *config.Config, musicUC domain.MusicUseCase, playerUC domain.PlayerUseCase, queueUC domain.QueueUseCase, playlistUC domain.PlaylistUseCase, permissionUC domain.PermissionUseCase, musicChannelUC domain.MusicChannelUseCase, settingsUC domain.SettingsUseCase, webhookUC domain.WebhookUseCase, scrobbleUC domain.ScrobbleUseCase, events domain.EventBus, log *logger.Logger) (*Server, error) {
+	logLevel := discordgo.LogError
+	switch cfg.LogLevel {
+	case logger.LevelDebug:
+		logLevel = discordgo.LogDebug
+	case logger.LevelInfo, logger.LevelWarn:
+		// discordgo is too chatty at its informational level
+		logLevel = discordgo.LogWarning
+	}
+	dlog := log.With("component", "discordgo")
+	discordgo.Logger = func(msgL, caller int, format string, a ...interface{}) {
+		level := logger.LevelDebug
+		switch msgL {
+		case discordgo.LogError:
+			level = logger.LevelError
+		case discordgo.LogWarning:
+			level = logger.LevelWarn
+		case discordgo.LogInformational:
+			level = logger.LevelInfo
+		}
+		dlog.Log(level, fmt.Sprintf(format, a...))
 	}
 
-	err = s.Open()
+	shards, shardCount, err := openShards(cfg, func(s *discordgo.Session) {
+		s.LogLevel = logLevel
+		if cfg.MessageContentIntent {
+			// required to read queries sent to music channels
+			s.Identify.Intents |= discordgo.IntentMessageContent
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
+	if shardCount > 1 {
+		ids := make([]int, 0, len(shards))
+		for _, s := range shards {
+			ids = append(ids, s.ShardID)
+		}
+		log.Info("init: shards opened", "shards", fmt.Sprint(ids), "shard_count", shardCount)
+	}
+	playerUC.Partition(shardCount)
 
-	_ = s.UpdateGameStatus(0, "github.com/daystram/caroline")
-
-	return &Server{
-		Session: s,
+	srv := &Server{
+		Session:    shards[0],
+		Shards:     shards,
+		ShardCount: shardCount,
 		UC: useCases{
-			Music:  musicUC,
-			Player: playerUC,
-			Queue:  queueUC,
+			Music:        musicUC,
+			Player:       playerUC,
+			Queue:        queueUC,
+			Playlist:     playlistUC,
+			Permission:   permissionUC,
+			MusicChannel: musicChannelUC,
+			Settings:     settingsUC,
+			Webhook:      webhookUC,
+			Scrobble:     scrobbleUC,
 		},
+		Events:       events,
+		Logger:       log,
 		StartTime:    time.Now(),
 		DebugGuildID: cfg.DebugGuildID,
-	}, nil
+
+		MessageContentIntent: cfg.MessageContentIntent,
+	}
+	srv.subscribeEvents()
+	srv.startPresence()
+	if cfg.HTTPAddr != "" {
+		srv.startHTTP(cfg.HTTPAddr, cfg.APIToken)
+	}
+
+	return srv, nil
+}
+
+// Log returns a logger scoped to an interaction, the interaction ID doubles as its request ID.
+func (s *Server) Log(i *discordgo.InteractionCreate) *logger.Logger {
+	return s.Logger.With(
+		"request_id", i.ID,
+		"guild", i.GuildID,
+		"user", i.Member.User.ID,
+		"type", i.Type.String(),
+		"interaction", util.InteractionName(i),
+	)
+}
+
+// subscribeEvents logs and counts every published event.
+func (s *Server) subscribeEvents() {
+	elog := s.Logger.With("component", "events")
+	s.Events.Subscribe("log", func(e domain.Event) {
+		kv := []interface{}{"event", event.Name(e), "guild", e.EventGuildID()}
+		switch e := e.(type) {
+		case domain.TrackLoading:
+			kv = append(kv, "music", e.Music.ID)
+		case domain.TrackStarted:
+			kv = append(kv, "music", e.Music.ID, "offset", e.Offset.Round(time.Second), "resumed", e.Resumed)
+		case domain.TrackEnded:
+			kv = append(kv, "music", e.Music.ID, "elapsed", e.Elapsed.Round(time.Second), "skipped", e.Skipped, "paused", e.Paused)
+		case domain.LoopChanged:
+			kv = append(kv, "mode", e.Mode.String())
+		case domain.PlayerStateChanged:
+			kv = append(kv, "status", e.Status.String())
+		}
+		elog.Debug("event", kv...)
+	})
+	s.Events.Subscribe("metrics", func(e domain.Event) {
+		metrics.Events.Inc(event.Name(e))
+	})
 }
 
 func (s *Server) Stop() error {
@@ -55,7 +160,15 @@ func (s *Server) Stop() error {
 		if err != nil {
 			continue
 		}
-		_ = s.UC.Player.Kick(s.Session, p, q)
+		_ = s.UC.Player.Kick(s.GuildSession(p.GuildID), p, q)
+	}
+	_ = s.stopHTTP()
+
+	var err error
+	for _, shard := range s.Shards {
+		if cerr := shard.Close(); cerr != nil {
+			err = cerr
+		}
 	}
-	return s.Session.Close()
+	return err
 }
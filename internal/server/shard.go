@@ -0,0 +1,143 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/config"
+	"github.com/daystram/caroline/internal/util"
+)
+
+// shardIdentifyInterval is how long Discord requires between identifies of the same rate limit bucket.
+const shardIdentifyInterval = 5 * time.Second
+
+// openShards opens a gateway session for every shard run by this process, being either the configured shard
+// or all of them. A zero shard count uses the count recommended by Discord.
+func openShards(cfg *config.Config, setup func(*discordgo.Session)) ([]*discordgo.Session, int, error) {
+	shardCount, concurrency := cfg.ShardCount, 1
+	if shardCount == 0 || (cfg.ShardID < 0 && shardCount > 1) {
+		s, err := discordgo.New(fmt.Sprintf("Bot %s", cfg.BotToken))
+		if err != nil {
+			return nil, 0, err
+		}
+		gw, err := s.GatewayBot()
+		if err != nil {
+			return nil, 0, fmt.Errorf("shards: %w", err)
+		}
+		if shardCount == 0 {
+			shardCount = gw.Shards
+		}
+		if gw.SessionStartLimit.MaxConcurrency > 1 {
+			concurrency = gw.SessionStartLimit.MaxConcurrency
+		}
+	}
+	if shardCount < 1 {
+		shardCount = 1
+	}
+
+	shardIDs := []int{cfg.ShardID}
+	if cfg.ShardID < 0 {
+		shardIDs = make([]int, 0, shardCount)
+		for id := 0; id < shardCount; id++ {
+			shardIDs = append(shardIDs, id)
+		}
+	}
+
+	shards := make([]*discordgo.Session, 0, len(shardIDs))
+	for n, id := range shardIDs {
+		if n > 0 && n%concurrency == 0 {
+			time.Sleep(shardIdentifyInterval)
+		}
+
+		s, err := discordgo.New(fmt.Sprintf("Bot %s", cfg.BotToken))
+		if err != nil {
+			closeShards(shards)
+			return nil, 0, err
+		}
+		s.ShardID, s.ShardCount = id, shardCount
+		setup(s)
+		err = s.Open()
+		if err != nil {
+			closeShards(shards)
+			return nil, 0, fmt.Errorf("shard %d: %w", id, err)
+		}
+		shards = append(shards, s)
+	}
+
+	return shards, shardCount, nil
+}
+
+func closeShards(shards []*discordgo.Session) {
+	for _, s := range shards {
+		_ = s.Close()
+	}
+}
+
+// AddHandler registers a gateway event handler on every shard, which is called with the receiving shard's session.
+func (s *Server) AddHandler(handler interface{}) {
+	for _, shard := range s.Shards {
+		shard.AddHandler(handler)
+	}
+}
+
+// GuildSession returns the session of the shard owning a guild, which holds its state and voice connections.
+// Guilds owned by another process fall back to the first shard, which can only be used for REST calls.
+func (s *Server) GuildSession(guildID string) *discordgo.Session {
+	id := util.ShardOf(guildID, s.ShardCount)
+	for _, shard := range s.Shards {
+		if shard.ShardID == id {
+			return shard
+		}
+	}
+	return s.Session
+}
+
+// OwnsAllShards reports whether every shard runs in this process, rather than being split across processes.
+func (s *Server) OwnsAllShards() bool {
+	return len(s.Shards) == s.ShardCount
+}
+
+// Ready reports whether every shard's gateway session is ready.
+func (s *Server) Ready() bool {
+	for _, shard := range s.Shards {
+		if !shard.DataReady {
+			return false
+		}
+	}
+	return true
+}
+
+// ShardStat summarizes a shard run by this process.
+type ShardStat struct {
+	ID       int
+	Guilds   int
+	Speakers int
+	Latency  time.Duration
+}
+
+// ShardStats summarizes every shard run by this process, with players counted towards their owning shard.
+func (s *Server) ShardStats() []ShardStat {
+	speakers := make(map[int]int)
+	for _, p := range s.UC.Player.GetAll() {
+		if util.IsPlayerReady(p) {
+			speakers[p.ShardID]++
+		}
+	}
+
+	stats := make([]ShardStat, 0, len(s.Shards))
+	for _, shard := range s.Shards {
+		shard.State.RLock()
+		guilds := len(shard.State.Guilds)
+		shard.State.RUnlock()
+		stats = append(stats, ShardStat{
+			ID:       shard.ShardID,
+			Guilds:   guilds,
+			Speakers: speakers[shard.ShardID],
+			Latency:  shard.HeartbeatLatency(),
+		})
+	}
+
+	return stats
+}
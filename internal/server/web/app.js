@@ -0,0 +1,163 @@
+"use strict";
+
+const tokenKey = "caroline-token";
+const guildsEl = document.getElementById("guilds");
+const emptyEl = document.getElementById("empty");
+const statusEl = document.getElementById("status");
+const loginEl = document.getElementById("login");
+const template = document.getElementById("guild-template");
+
+let guilds = [];
+let dragFrom = null;
+
+function formatDuration(seconds) {
+  seconds = Math.max(0, Math.floor(seconds));
+  const m = Math.floor(seconds / 60);
+  const s = String(seconds % 60).padStart(2, "0");
+  return `${m}:${s}`;
+}
+
+function setStatus(text, connected) {
+  statusEl.textContent = text;
+  statusEl.classList.toggle("connected", connected);
+}
+
+async function move(guildID, from, to) {
+  const resp = await fetch(`/api/guilds/${guildID}/move`, {
+    method: "POST",
+    headers: {
+      "Authorization": `Bearer ${localStorage.getItem(tokenKey)}`,
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ from, to }),
+  });
+  if (!resp.ok) {
+    const body = await resp.json().catch(() => ({}));
+    alert(`Could not move track: ${body.error || resp.statusText}`);
+  }
+}
+
+function renderQueue(list, g) {
+  list.replaceChildren();
+  for (const track of g.queue.tracks) {
+    const item = document.createElement("li");
+    item.value = track.position;
+    item.textContent = track.title;
+    if (track.position === g.queue.current_position) {
+      item.classList.add("current");
+      list.append(item);
+      continue;
+    }
+
+    item.draggable = true;
+    item.addEventListener("dragstart", () => {
+      dragFrom = track.position;
+      item.classList.add("dragging");
+    });
+    item.addEventListener("dragend", () => {
+      dragFrom = null;
+      item.classList.remove("dragging");
+    });
+    item.addEventListener("dragover", (e) => {
+      e.preventDefault();
+      item.classList.add("over");
+    });
+    item.addEventListener("dragleave", () => item.classList.remove("over"));
+    item.addEventListener("drop", (e) => {
+      e.preventDefault();
+      item.classList.remove("over");
+      if (dragFrom !== null && dragFrom !== track.position) {
+        move(g.guild_id, dragFrom, track.position);
+      }
+    });
+    list.append(item);
+  }
+}
+
+function render() {
+  emptyEl.hidden = guilds.length > 0;
+  guildsEl.replaceChildren();
+  for (const g of guilds) {
+    const el = template.content.firstElementChild.cloneNode(true);
+    el.dataset.guild = g.guild_id;
+    el.querySelector(".name").textContent = g.name || g.guild_id;
+    const icon = el.querySelector(".icon");
+    if (g.icon) {
+      icon.src = g.icon;
+    } else {
+      icon.hidden = true;
+    }
+
+    const np = g.player.now_playing;
+    el.querySelector(".np-title").textContent = np ? np.title : "Nothing playing";
+    el.querySelector(".np-meta").textContent = [
+      g.player.status,
+      `loop ${g.player.loop}`,
+      `shuffle ${g.player.shuffle}`,
+      `autoplay ${g.player.autoplay}`,
+    ].join(" · ");
+
+    renderQueue(el.querySelector(".queue"), g);
+    guildsEl.append(el);
+  }
+  renderProgress();
+}
+
+function renderProgress() {
+  for (const g of guilds) {
+    const el = guildsEl.querySelector(`[data-guild="${g.guild_id}"]`);
+    if (!el) {
+      continue;
+    }
+    const np = g.player.now_playing;
+    const duration = np && np.duration ? np.duration : 0;
+    const elapsed = g.player.started_at ? (Date.now() - Date.parse(g.player.started_at)) / 1000 : 0;
+    const ratio = duration > 0 ? Math.min(1, elapsed / duration) : 0;
+    el.querySelector(".progress-bar").style.width = `${ratio * 100}%`;
+    el.querySelector(".progress-time").textContent = duration > 0
+      ? `${formatDuration(Math.min(elapsed, duration))} / ${formatDuration(duration)}`
+      : "";
+  }
+}
+
+function connect() {
+  const token = localStorage.getItem(tokenKey);
+  if (!token) {
+    loginEl.hidden = false;
+    return;
+  }
+
+  const scheme = location.protocol === "https:" ? "wss" : "ws";
+  const ws = new WebSocket(`${scheme}://${location.host}/dashboard/ws?token=${encodeURIComponent(token)}`);
+  let opened = false;
+  setStatus("connecting", false);
+
+  ws.addEventListener("open", () => {
+    opened = true;
+    loginEl.hidden = true;
+    setStatus("connected", true);
+  });
+  ws.addEventListener("message", (e) => {
+    guilds = JSON.parse(e.data).guilds;
+    render();
+  });
+  ws.addEventListener("close", () => {
+    setStatus("disconnected", false);
+    if (!opened) {
+      // most likely a rejected token
+      localStorage.removeItem(tokenKey);
+      loginEl.hidden = false;
+      return;
+    }
+    setTimeout(connect, 2000);
+  });
+}
+
+loginEl.addEventListener("submit", (e) => {
+  e.preventDefault();
+  localStorage.setItem(tokenKey, document.getElementById("token").value);
+  connect();
+});
+
+setInterval(renderProgress, 500);
+connect();
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+const (
+	exportVersion   = 1
+	exportTitle     = "caroline queue"
+	importMaxTracks = 1000
+
+	spotifyTrackURLPattern = "https://open.spotify.com/track/"
+	youtubeVideoURLPattern = "https://youtu.be/"
+	xspfNamespace          = "http://xspf.org/ns/0/"
+)
+
+var (
+	exportIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+type exportDocument struct {
+	Version int           `json:"version"`
+	Tracks  []exportTrack `json:"tracks"`
+}
+
+type exportTrack struct {
+	Title          string `json:"title,omitempty"`
+	Query          string `json:"query,omitempty"`
+	URL            string `json:"url,omitempty"`
+	Source         string `json:"source"`
+	SpotifyTrackID string `json:"spotify_track_id,omitempty"`
+	YouTubeVideoID string `json:"youtube_video_id,omitempty"`
+	Duration       int    `json:"duration,omitempty"` // in seconds
+}
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Xmlns   string      `xml:"xmlns,attr,omitempty"`
+	Version string      `xml:"version,attr"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location   []string `xml:"location,omitempty"`
+	Title      string   `xml:"title,omitempty"`
+	Annotation string   `xml:"annotation,omitempty"`
+	Duration   int64    `xml:"duration,omitempty"` // in milliseconds
+}
+
+func (u *musicUseCase) Export(musics []*domain.Music, format domain.MusicFormat) ([]byte, error) {
+	switch format {
+	case domain.MusicFormatJSON:
+		doc := exportDocument{
+			Version: exportVersion,
+			Tracks:  make([]exportTrack, 0, len(musics)),
+		}
+		for _, m := range musics {
+			t := exportTrack{
+				Query:          m.Query,
+				Source:         m.Source.String(),
+				SpotifyTrackID: m.SpotifyTrackID,
+				YouTubeVideoID: m.YouTubeVideoID,
+			}
+			if m.Loaded {
+				t.Title = m.Title
+				t.URL = m.URL
+				t.Duration = int(m.Duration / time.Second)
+			}
+			doc.Tracks = append(doc.Tracks, t)
+		}
+		return json.MarshalIndent(doc, "", "  ")
+
+	case domain.MusicFormatM3U:
+		var b bytes.Buffer
+		b.WriteString("#EXTM3U\n")
+		for _, m := range musics {
+			title, duration := m.Query, -1
+			if m.Loaded {
+				title, duration = m.Title, int(m.Duration/time.Second)
+			}
+			locations := musicLocations(m)
+			location := m.Query
+			if len(locations) > 0 {
+				location = locations[0]
+			}
+			b.WriteString(fmt.Sprintf("#EXTINF:%d,%s\n%s\n", duration, singleLine(title), singleLine(location)))
+		}
+		return b.Bytes(), nil
+
+	case domain.MusicFormatXSPF:
+		doc := xspfPlaylist{
+			Xmlns:   xspfNamespace,
+			Version: "1",
+			Title:   exportTitle,
+			Tracks:  make([]xspfTrack, 0, len(musics)),
+		}
+		for _, m := range musics {
+			t := xspfTrack{
+				Location:   musicLocations(m),
+				Annotation: m.Query,
+			}
+			if m.Loaded {
+				t.Title = m.Title
+				t.Duration = m.Duration.Milliseconds()
+			}
+			doc.Tracks = append(doc.Tracks, t)
+		}
+		b, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), b...), nil
+
+	default:
+		return nil, domain.ErrBadFormat
+	}
+}
+
+func (u *musicUseCase) Import(data []byte, format domain.MusicFormat, user *discordgo.User) ([]*domain.Music, error) {
+	// every entry is resolved back into a query, so imported files can only
+	// reference the same sources that could be queued with /p
+	queries := make([]string, 0)
+	switch format {
+	case domain.MusicFormatJSON:
+		doc := exportDocument{}
+		err := json.Unmarshal(data, &doc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrBadFormat, err)
+		}
+		for _, t := range doc.Tracks {
+			var query string
+			switch {
+			case exportIDRegex.MatchString(t.SpotifyTrackID):
+				query = spotifyTrackURLPattern + t.SpotifyTrackID
+			case exportIDRegex.MatchString(t.YouTubeVideoID):
+				query = youtubeVideoURLPattern + t.YouTubeVideoID
+			case t.URL != "":
+				query = t.URL
+			case t.Title != "":
+				query = t.Title
+			default:
+				query = t.Query
+			}
+			queries = append(queries, query)
+		}
+
+	case domain.MusicFormatM3U:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			queries = append(queries, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrBadFormat, err)
+		}
+
+	case domain.MusicFormatXSPF:
+		doc := xspfPlaylist{}
+		err := xml.Unmarshal(data, &doc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrBadFormat, err)
+		}
+		for _, t := range doc.Tracks {
+			var query string
+			switch {
+			case len(t.Location) > 0:
+				query = t.Location[0]
+			case t.Title != "":
+				query = t.Title
+			default:
+				query = t.Annotation
+			}
+			queries = append(queries, query)
+		}
+
+	default:
+		return nil, domain.ErrBadFormat
+	}
+
+	musics := make([]*domain.Music, 0, len(queries))
+	for _, q := range queries {
+		q = singleLine(strings.TrimSpace(q))
+		if q == "" || (!u.musicRepo.SpotifyEnabled() && isSpotifyQuery(q)) {
+			continue
+		}
+		musics = append(musics, newMusic(q, user))
+		if len(musics) == importMaxTracks {
+			break
+		}
+	}
+	if len(musics) == 0 {
+		return nil, domain.ErrBadFormat
+	}
+
+	return musics, nil
+}
+
+func musicLocations(m *domain.Music) []string {
+	locations := make([]string, 0)
+	if m.SpotifyTrackID != "" {
+		locations = append(locations, spotifyTrackURLPattern+m.SpotifyTrackID)
+	}
+	if m.YouTubeVideoID != "" {
+		locations = append(locations, youtubeVideoURLPattern+m.YouTubeVideoID)
+	} else if m.Loaded && m.URL != "" {
+		locations = append(locations, m.URL)
+	}
+	return locations
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+func TestImport(t *testing.T) {
+	user := &discordgo.User{ID: "1", Username: "user"}
+	tests := []struct {
+		name    string
+		format  domain.MusicFormat
+		data    string
+		spotify bool
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "json prefers ids",
+			format: domain.MusicFormatJSON,
+			data: `{"version": 1, "tracks": [
+				{"query": "a", "spotify_track_id": "sp1", "youtube_video_id": "yt1"},
+				{"query": "b", "youtube_video_id": "yt2", "url": "https://example.com/b"},
+				{"query": "c", "url": "https://example.com/c", "title": "C"},
+				{"query": "d", "title": "D"},
+				{"query": "e"}
+			]}`,
+			spotify: true,
+			want: []string{
+				"https://open.spotify.com/track/sp1",
+				"https://youtu.be/yt2",
+				"https://example.com/c",
+				"D",
+				"e",
+			},
+		},
+		{
+			name:   "json ignores malformed ids",
+			format: domain.MusicFormatJSON,
+			data:   `{"tracks": [{"query": "a", "youtube_video_id": "../x"}]}`,
+			want:   []string{"a"},
+		},
+		{
+			name:    "json invalid",
+			format:  domain.MusicFormatJSON,
+			data:    `{"tracks": [`,
+			wantErr: domain.ErrBadFormat,
+		},
+		{
+			name:   "m3u skips comments and blank lines",
+			format: domain.MusicFormatM3U,
+			data:   "#EXTM3U\n#EXTINF:120,A\nhttps://youtu.be/yt1\n\n  \n#EXTINF:-1,B\nsome   query\n",
+			want:   []string{"https://youtu.be/yt1", "some query"},
+		},
+		{
+			name:    "m3u without entries",
+			format:  domain.MusicFormatM3U,
+			data:    "#EXTM3U\n",
+			wantErr: domain.ErrBadFormat,
+		},
+		{
+			name:   "xspf prefers location",
+			format: domain.MusicFormatXSPF,
+			data: `<?xml version="1.0"?><playlist version="1" xmlns="http://xspf.org/ns/0/"><trackList>
+				<track><location>https://youtu.be/yt1</location><title>A</title></track>
+				<track><title>B</title><annotation>b</annotation></track>
+				<track><annotation>c</annotation></track>
+			</trackList></playlist>`,
+			want: []string{"https://youtu.be/yt1", "B", "c"},
+		},
+		{
+			name:    "spotify skipped when disabled",
+			format:  domain.MusicFormatM3U,
+			data:    "https://open.spotify.com/track/sp1\nquery\n",
+			spotify: false,
+			want:    []string{"query"},
+		},
+		{
+			name:    "unknown format",
+			format:  domain.MusicFormat(99),
+			data:    "query",
+			wantErr: domain.ErrBadFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &musicUseCase{musicRepo: &fakeMusicRepository{spotifyEnabled: tt.spotify}}
+			musics, err := u.Import([]byte(tt.data), tt.format, user)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Import() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			got := make([]string, 0, len(musics))
+			for _, m := range musics {
+				got = append(got, m.Query)
+				if m.QueuedByID != user.ID {
+					t.Errorf("Import() queued by %q, want %q", m.QueuedByID, user.ID)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Import() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	user := &discordgo.User{ID: "1", Username: "user"}
+	musics := []*domain.Music{
+		{Query: "https://youtu.be/yt1", Source: domain.MusicSourceYouTubeVideo, YouTubeVideoID: "yt1"},
+		{Query: "https://open.spotify.com/track/sp1", Source: domain.MusicSourceSpotifyTrack, SpotifyTrackID: "sp1"},
+		{Query: "search", Source: domain.MusicSourceSearch, Loaded: true, Title: "Song", URL: "https://example.com/song", Duration: 3 * time.Minute},
+		{Query: "not loaded", Source: domain.MusicSourceSearch},
+	}
+	want := []string{
+		"https://youtu.be/yt1",
+		"https://open.spotify.com/track/sp1",
+		"https://example.com/song",
+		"not loaded",
+	}
+
+	for _, format := range []domain.MusicFormat{domain.MusicFormatJSON, domain.MusicFormatM3U, domain.MusicFormatXSPF} {
+		t.Run(format.String(), func(t *testing.T) {
+			u := &musicUseCase{musicRepo: &fakeMusicRepository{spotifyEnabled: true}}
+			data, err := u.Export(musics, format)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			imported, err := u.Import(data, format, user)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			got := make([]string, 0, len(imported))
+			for _, m := range imported {
+				got = append(got, m.Query)
+			}
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("Import(Export()) = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"sync"
+
+	"github.com/zmb3/spotify/v2"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+// fakeMusicRepository loads musics from a fixed table instead of calling out to Spotify and yt-dlp.
+type fakeMusicRepository struct {
+	spotifyEnabled bool
+	// loaded maps a query to the music it resolves to, queries missing from it fail to load
+	loaded map[string]domain.Music
+	loads  int
+	lock   sync.Mutex
+}
+
+var _ domain.MusicRepository = (*fakeMusicRepository)(nil)
+
+func (r *fakeMusicRepository) SpotifyEnabled() bool {
+	return r.spotifyEnabled
+}
+
+func (r *fakeMusicRepository) GetSpotifyPlaylist(id string) (*spotify.FullPlaylist, []spotify.PlaylistTrack, error) {
+	return nil, nil, domain.ErrSpotifyDisabled
+}
+
+func (r *fakeMusicRepository) GetRelated(seeds []*domain.Music, limit int) ([]*domain.Music, error) {
+	return nil, nil
+}
+
+func (r *fakeMusicRepository) Load(music *domain.Music) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.loads++
+	m, ok := r.loaded[music.Query]
+	if !ok {
+		return domain.ErrMusicNotFound
+	}
+	music.Loaded = true
+	music.Title, music.URL, music.Duration = m.Title, m.URL, m.Duration
+	return nil
+}
+
+func (r *fakeMusicRepository) GetStreamURL(music *domain.Music) (string, error) {
+	return music.URL, nil
+}
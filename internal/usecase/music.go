@@ -33,6 +33,9 @@ var _ domain.MusicUseCase = (*musicUseCase)(nil)
 func (u *musicUseCase) Parse(query string, user *discordgo.User) (string, []*domain.Music, error) {
 	meta := ""
 	query = strings.TrimSpace(query)
+	if !u.musicRepo.SpotifyEnabled() && isSpotifyQuery(query) {
+		return "", nil, domain.ErrSpotifyDisabled
+	}
 
 	musics := make([]*domain.Music, 0)
 	switch {
@@ -55,40 +58,38 @@ func (u *musicUseCase) Parse(query string, user *discordgo.User) (string, []*dom
 		}
 		meta = p.Name
 
+	default:
+		musics = append(musics, newMusic(query, user))
+	}
+
+	return meta, musics, nil
+}
+
+func isSpotifyQuery(query string) bool {
+	return spotifyPlaylistRegex.MatchString(query) || spotifyTrackRegex.MatchString(query)
+}
+
+func newMusic(query string, user *discordgo.User) *domain.Music {
+	m := &domain.Music{
+		ID:               uuid.NewString(),
+		Query:            query,
+		QueuedAt:         time.Now(),
+		QueuedByID:       user.ID,
+		QueuedByUsername: user.Username,
+	}
+
+	switch {
 	case spotifyTrackRegex.MatchString(query):
-		trackID := spotifyTrackRegex.FindStringSubmatch(query)[spotifyTrackRegex.SubexpIndex("trackID")]
-		musics = append(musics, &domain.Music{
-			ID:               uuid.NewString(),
-			Query:            query,
-			QueuedAt:         time.Now(),
-			QueuedByID:       user.ID,
-			QueuedByUsername: user.Username,
-			Source:           domain.MusicSourceSpotifyTrack,
-			SpotifyTrackID:   trackID,
-		})
+		m.Source = domain.MusicSourceSpotifyTrack
+		m.SpotifyTrackID = spotifyTrackRegex.FindStringSubmatch(query)[spotifyTrackRegex.SubexpIndex("trackID")]
 
 	case youtubeVideoRegex.MatchString(query):
-		videoID := youtubeVideoRegex.FindStringSubmatch(query)[youtubeVideoRegex.SubexpIndex("videoID")]
-		musics = append(musics, &domain.Music{
-			ID:               uuid.NewString(),
-			Query:            query,
-			QueuedAt:         time.Now(),
-			QueuedByID:       user.ID,
-			QueuedByUsername: user.Username,
-			Source:           domain.MusicSourceYouTubeVideo,
-			YouTubeVideoID:   videoID,
-		})
+		m.Source = domain.MusicSourceYouTubeVideo
+		m.YouTubeVideoID = youtubeVideoRegex.FindStringSubmatch(query)[youtubeVideoRegex.SubexpIndex("videoID")]
 
 	default:
-		musics = append(musics, &domain.Music{
-			ID:               uuid.NewString(),
-			Query:            query,
-			QueuedAt:         time.Now(),
-			QueuedByID:       user.ID,
-			QueuedByUsername: user.Username,
-			Source:           domain.MusicSourceSearch,
-		})
+		m.Source = domain.MusicSourceSearch
 	}
 
-	return meta, musics, nil
+	return m
 }
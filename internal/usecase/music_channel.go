@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+func NewMusicChannelUseCase(musicChannelRepo domain.MusicChannelRepository) (domain.MusicChannelUseCase, error) {
+	return &musicChannelUseCase{
+		musicChannelRepo: musicChannelRepo,
+	}, nil
+}
+
+type musicChannelUseCase struct {
+	musicChannelRepo domain.MusicChannelRepository
+}
+
+var _ domain.MusicChannelUseCase = (*musicChannelUseCase)(nil)
+
+func (u *musicChannelUseCase) Get(guildID string) (*domain.MusicChannel, error) {
+	return u.musicChannelRepo.Get(guildID)
+}
+
+func (u *musicChannelUseCase) Set(guildID, channelID string, queries bool) (*domain.MusicChannel, error) {
+	mc := &domain.MusicChannel{
+		GuildID:   guildID,
+		ChannelID: channelID,
+		Queries:   queries,
+	}
+
+	// keep the existing player message when only toggling queries
+	prev, err := u.musicChannelRepo.Get(guildID)
+	if err != nil && !errors.Is(err, domain.ErrMusicChannelNotFound) {
+		return nil, err
+	}
+	if prev != nil && prev.ChannelID == channelID {
+		mc.MessageID = prev.MessageID
+	}
+
+	err = u.musicChannelRepo.Save(mc)
+	if err != nil {
+		return nil, err
+	}
+
+	return mc, nil
+}
+
+func (u *musicChannelUseCase) Unset(guildID string) (*domain.MusicChannel, error) {
+	mc, err := u.musicChannelRepo.Get(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.musicChannelRepo.Delete(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mc, nil
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/util"
+)
+
+func NewPermissionUseCase(settingsRepo domain.SettingsRepository) (domain.PermissionUseCase, error) {
+	return &permissionUseCase{
+		settingsRepo: settingsRepo,
+		votes:        make(map[string]*skipVote),
+	}, nil
+}
+
+type permissionUseCase struct {
+	settingsRepo domain.SettingsRepository
+
+	votes map[string]*skipVote
+	lock  sync.Mutex
+}
+
+var _ domain.PermissionUseCase = (*permissionUseCase)(nil)
+
+type skipVote struct {
+	musicID string
+	voters  map[string]bool
+}
+
+func (u *permissionUseCase) IsDJ(s *discordgo.Session, guildID string, member *discordgo.Member) bool {
+	if member == nil {
+		return false
+	}
+	if member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) != 0 {
+		return true
+	}
+	settings, err := u.settingsRepo.Get(guildID)
+	if err != nil || settings.DJRole == "" {
+		return false
+	}
+
+	for _, roleID := range member.Roles {
+		if roleID == settings.DJRole {
+			return true
+		}
+		role, err := s.State.Role(guildID, roleID)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(role.Name, settings.DJRole) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (u *permissionUseCase) Check(s *discordgo.Session, p *domain.Player, q *domain.Queue, member *discordgo.Member, action domain.PermissionAction, target *domain.Music) domain.PermissionResult {
+	if u.IsDJ(s, p.GuildID, member) {
+		return domain.PermissionGranted
+	}
+	// nobody else to disturb
+	if util.CountListeners(s, p) <= 1 {
+		return domain.PermissionGranted
+	}
+
+	switch action {
+	case domain.PermissionActionControl:
+		return domain.PermissionGranted
+	case domain.PermissionActionSkip:
+		if target != nil && target.QueuedByID == member.User.ID {
+			return domain.PermissionGranted
+		}
+		return domain.PermissionVoteRequired
+	case domain.PermissionActionPrevious:
+		// going back cuts the current track short, like skipping it
+		if target != nil && target.QueuedByID == member.User.ID {
+			return domain.PermissionGranted
+		}
+		return domain.PermissionDenied
+	case domain.PermissionActionRemove, domain.PermissionActionMove:
+		if target != nil && target.QueuedByID == member.User.ID {
+			return domain.PermissionGranted
+		}
+		return domain.PermissionDenied
+	default:
+		return domain.PermissionDenied
+	}
+}
+
+func (u *permissionUseCase) VoteSkip(s *discordgo.Session, p *domain.Player, q *domain.Queue, member *discordgo.Member) (int, int, bool, error) {
+	settings, err := u.settingsRepo.Get(p.GuildID)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	music := q.NowPlaying()
+	if music == nil {
+		return 0, 0, false, domain.ErrNotPlaying
+	}
+
+	// votes are only valid for the track they were cast on
+	v, ok := u.votes[p.GuildID]
+	if !ok || v.musicID != music.ID {
+		v = &skipVote{
+			musicID: music.ID,
+			voters:  make(map[string]bool),
+		}
+		u.votes[p.GuildID] = v
+	}
+	v.voters[member.User.ID] = true
+
+	// votes of listeners who have left no longer count
+	listeners := util.ListenerIDs(s, p.GuildID, p.VoiceChannel.ID)
+	present := make(map[string]bool, len(listeners))
+	for _, userID := range listeners {
+		present[userID] = true
+	}
+	for userID := range v.voters {
+		if !present[userID] {
+			delete(v.voters, userID)
+		}
+	}
+
+	required := int(math.Ceil(float64(len(listeners)) * settings.VoteSkipRatio))
+	if required < 1 {
+		required = 1
+	}
+	votes := len(v.voters)
+	passed := votes >= required
+	if passed {
+		delete(u.votes, p.GuildID)
+	}
+
+	return votes, required, passed, nil
+}
@@ -3,51 +3,123 @@ package usecase
 import (
 	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/daystram/dgvoice"
+	"github.com/google/uuid"
 
 	"github.com/daystram/caroline/internal/common"
 	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/metrics"
 	"github.com/daystram/caroline/internal/util"
 )
 
-func NewPlayerUseCase(musicRepo domain.MusicRepository, queueRepo domain.QueueRepository) (domain.PlayerUseCase, error) {
+const (
+	autoplaySeedSize  = 5
+	autoplayBatchSize = 5
+
+	healthCheckInterval  = 5 * time.Second
+	reconnectMaxAttempts = 5
+	reconnectBaseBackoff = time.Second
+
+	stageInstanceTopic = "Now Playing"
+)
+
+var errSpeakerKicked = errors.New("speaker kicked")
+
+func NewPlayerUseCase(musicRepo domain.MusicRepository, voiceRepo domain.VoiceRepository, queueRepo domain.QueueRepository, musicChannelRepo domain.MusicChannelRepository, settingsRepo domain.SettingsRepository, bus domain.EventBus, log *logger.Logger) (domain.PlayerUseCase, error) {
+	vlog := log.With("component", "dgvoice")
 	dgvoice.OnError = func(str string, err error) {
 		if err != nil {
-			log.Println("player:", err)
+			vlog.Error(str, "err", err)
 		}
 	}
 
 	return &playerUseCase{
-		musicRepo: musicRepo,
-		queueRepo: queueRepo,
-		speakers:  make(map[string]*speaker),
+		musicRepo:        musicRepo,
+		voiceRepo:        voiceRepo,
+		queueRepo:        queueRepo,
+		musicChannelRepo: musicChannelRepo,
+		settingsRepo:     settingsRepo,
+		bus:              bus,
+		log:              log,
+		partitions:       newSpeakerPartitions(1),
 	}, nil
 }
 
 type playerUseCase struct {
-	musicRepo domain.MusicRepository
-	queueRepo domain.QueueRepository
+	musicRepo        domain.MusicRepository
+	voiceRepo        domain.VoiceRepository
+	queueRepo        domain.QueueRepository
+	musicChannelRepo domain.MusicChannelRepository
+	settingsRepo     domain.SettingsRepository
 
+	bus domain.EventBus
+	log *logger.Logger
+
+	// partitions hold the speakers of each shard, so that players on different shards do not contend
+	partitions []*speakerPartition
+}
+
+var _ domain.PlayerUseCase = (*playerUseCase)(nil)
+
+type speakerPartition struct {
 	speakers map[string]*speaker
 	lock     sync.RWMutex
 }
 
-var _ domain.PlayerUseCase = (*playerUseCase)(nil)
+func newSpeakerPartitions(shardCount int) []*speakerPartition {
+	partitions := make([]*speakerPartition, shardCount)
+	for i := range partitions {
+		partitions[i] = &speakerPartition{speakers: make(map[string]*speaker)}
+	}
+	return partitions
+}
+
+// Partition splits players by owning shard, it has to be called before any player is created.
+func (u *playerUseCase) Partition(shardCount int) {
+	if shardCount < 1 {
+		shardCount = 1
+	}
+	u.partitions = newSpeakerPartitions(shardCount)
+}
+
+func (u *playerUseCase) partition(guildID string) *speakerPartition {
+	return u.partitions[util.ShardOf(guildID, len(u.partitions))]
+}
+
+func (u *playerUseCase) workerLog(guildID string) *logger.Logger {
+	return u.log.With("guild", guildID, "worker", "speaker")
+}
 
 type speaker struct {
 	*domain.Player
 
 	playtime time.Duration
 	action   chan domain.PlayerAction
+
+	// streamOffset is the position the current track started streaming from, to account only the time actually played
+	streamOffset time.Duration
+	// listened is the time the current track has streamed, across pauses and reconnects
+	listened time.Duration
+
+	idleTimer  *time.Timer
+	autoPaused bool
+	idleLock   sync.Mutex
+
+	resumeMusicID string
+	resumeOffset  time.Duration
+
+	// stageChannelID is the Stage channel whose instance was started by the bot, to be ended when leaving
+	stageChannelID string
 }
 
-func (sp *speaker) Initialize(s *discordgo.Session) error {
-	conn, err := s.ChannelVoiceJoin(sp.VoiceChannel.GuildID, sp.VoiceChannel.ID, false, true)
+func (sp *speaker) Initialize(s *discordgo.Session, voiceRepo domain.VoiceRepository) error {
+	conn, err := voiceRepo.Join(s, sp.VoiceChannel.GuildID, sp.VoiceChannel.ID)
 	if err != nil {
 		return err
 	}
@@ -59,38 +131,54 @@ func (sp *speaker) Initialize(s *discordgo.Session) error {
 }
 
 func (sp *speaker) Uninitialize() error {
-	_ = sp.Conn.Disconnect()
+	if sp.Conn != nil {
+		_ = sp.Conn.Disconnect()
+	}
 	sp.Conn = nil
 	sp.Status = domain.PlayerStatusUninitialized
 	return nil
 }
 
 func (u *playerUseCase) Create(s *discordgo.Session, vch, npch *discordgo.Channel, q *domain.Queue) (*domain.Player, error) {
-	u.lock.Lock()
-	defer u.lock.Unlock()
+	ps := u.partition(vch.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
 
-	sp, ok := u.speakers[vch.GuildID]
+	settings, err := u.settingsRepo.Get(vch.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.AnnounceChannelID != "" {
+		if ach, err := s.Channel(settings.AnnounceChannelID); err == nil {
+			npch = ach
+		}
+	}
+
+	sp, ok := ps.speakers[vch.GuildID]
 	if !ok {
 		sp = &speaker{
 			Player: &domain.Player{
 				GuildID:      vch.GuildID,
+				ShardID:      s.ShardID,
 				VoiceChannel: vch,
 				NPChannel:    npch,
 				Status:       domain.PlayerStatusUninitialized,
 			},
 			action: make(chan domain.PlayerAction),
 		}
-		u.speakers[vch.GuildID] = sp
+		ps.speakers[vch.GuildID] = sp
 	}
 	if sp.Status == domain.PlayerStatusUninitialized {
-		err := sp.Initialize(s)
+		err := sp.Initialize(s, u.voiceRepo)
 		if err != nil {
+			delete(ps.speakers, vch.GuildID)
 			return nil, err
 		}
+		u.publishState(sp)
 		go func() {
 			err := u.startSpeakerWorker(s, sp, q)
 			if err != nil {
-				log.Println(err)
+				u.workerLog(sp.GuildID).Error("worker stopped", "err", err)
 			}
 		}()
 	}
@@ -99,10 +187,11 @@ func (u *playerUseCase) Create(s *discordgo.Session, vch, npch *discordgo.Channe
 }
 
 func (u *playerUseCase) Get(guildID string) (*domain.Player, error) {
-	u.lock.RLock()
-	defer u.lock.RUnlock()
+	ps := u.partition(guildID)
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
 
-	sp, ok := u.speakers[guildID]
+	sp, ok := ps.speakers[guildID]
 	if !ok {
 		return nil, domain.ErrNotPlaying
 	}
@@ -111,22 +200,24 @@ func (u *playerUseCase) Get(guildID string) (*domain.Player, error) {
 }
 
 func (u *playerUseCase) GetAll() []*domain.Player {
-	u.lock.RLock()
-	defer u.lock.RUnlock()
-
-	players := make([]*domain.Player, 0, len(u.speakers))
-	for _, sp := range u.speakers {
-		players = append(players, sp.Player)
+	players := make([]*domain.Player, 0)
+	for _, ps := range u.partitions {
+		ps.lock.RLock()
+		for _, sp := range ps.speakers {
+			players = append(players, sp.Player)
+		}
+		ps.lock.RUnlock()
 	}
 
 	return players
 }
 
 func (u *playerUseCase) Play(p *domain.Player) error {
-	u.lock.Lock()
-	defer u.lock.Unlock()
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
 
-	sp, ok := u.speakers[p.GuildID]
+	sp, ok := ps.speakers[p.GuildID]
 	if !ok || sp.Status == domain.PlayerStatusUninitialized {
 		return domain.ErrNotPlaying
 	}
@@ -134,17 +225,19 @@ func (u *playerUseCase) Play(p *domain.Player) error {
 	if sp.Status != domain.PlayerStatusPlaying {
 		sp.action <- domain.PlayerActionPlay
 	}
+	sp.cancelIdle()
 	return nil
 }
 
 func (u *playerUseCase) Skip(p *domain.Player) error {
-	u.lock.Lock()
-	defer u.lock.Unlock()
 	if p == nil {
 		return domain.ErrNotPlaying
 	}
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
 
-	sp, ok := u.speakers[p.GuildID]
+	sp, ok := ps.speakers[p.GuildID]
 	if !ok || sp.Status == domain.PlayerStatusUninitialized {
 		return domain.ErrNotPlaying
 	}
@@ -154,56 +247,230 @@ func (u *playerUseCase) Skip(p *domain.Player) error {
 }
 
 func (u *playerUseCase) Stop(p *domain.Player) error {
-	u.lock.Lock()
-	defer u.lock.Unlock()
 	if p == nil {
 		return domain.ErrNotPlaying
 	}
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
 
-	sp, ok := u.speakers[p.GuildID]
+	sp, ok := ps.speakers[p.GuildID]
 	if !ok || sp.Status == domain.PlayerStatusUninitialized {
 		return domain.ErrNotPlaying
 	}
 
-	sp.CurrentStartTime = time.Time{}
 	sp.Status = domain.PlayerStatusStopped
 	sp.action <- domain.PlayerActionStop
 	return nil
 }
 
+// pause stops the player, keeping the position of the current track for the next play.
+func (u *playerUseCase) pause(p *domain.Player) error {
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	sp, ok := ps.speakers[p.GuildID]
+	if !ok || sp.Status != domain.PlayerStatusPlaying {
+		return domain.ErrNotPlaying
+	}
+
+	sp.Status = domain.PlayerStatusStopped
+	sp.action <- domain.PlayerActionPause
+	return nil
+}
+
+func (u *playerUseCase) MoveVoiceChannel(p *domain.Player, vch *discordgo.Channel) error {
+	if p == nil {
+		return domain.ErrNotPlaying
+	}
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	sp, ok := ps.speakers[p.GuildID]
+	if !ok || sp.Status == domain.PlayerStatusUninitialized || sp.Conn == nil {
+		return domain.ErrNotPlaying
+	}
+	if sp.VoiceChannel.ID == vch.ID {
+		return nil
+	}
+
+	err := sp.Conn.ChangeChannel(vch.ID)
+	if err != nil {
+		return err
+	}
+	sp.VoiceChannel = vch
+	u.publishState(sp)
+	return nil
+}
+
+func (u *playerUseCase) MoveNPChannel(s *discordgo.Session, p *domain.Player, q *domain.Queue, npch *discordgo.Channel) error {
+	if p == nil {
+		return domain.ErrNotPlaying
+	}
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	sp, ok := ps.speakers[p.GuildID]
+	if !ok || sp.Status == domain.PlayerStatusUninitialized {
+		return domain.ErrNotPlaying
+	}
+
+	if sp.LastNPMessageID != "" {
+		_ = s.ChannelMessageDelete(sp.NPChannel.ID, sp.LastNPMessageID)
+	}
+	sp.NPChannel = npch
+	sp.LastNPMessageID = ""
+	return u.UpdateNPMessage(s, sp.Player, q, -1, false, true)
+}
+
+func (u *playerUseCase) UpdateVoiceState(s *discordgo.Session, p *domain.Player, q *domain.Queue, vs *discordgo.VoiceState) error {
+	if p == nil {
+		return domain.ErrNotPlaying
+	}
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	sp, ok := ps.speakers[p.GuildID]
+	if !ok || sp.Status == domain.PlayerStatusUninitialized {
+		return domain.ErrNotPlaying
+	}
+
+	if vs.ChannelID == "" {
+		if sp.ReconnectAttempt > 0 {
+			// caused by our own reconnect
+			return nil
+		}
+		if cur, err := s.State.VoiceState(sp.GuildID, s.State.User.ID); err == nil && cur.ChannelID != "" {
+			// stale update, already rejoined
+			return nil
+		}
+
+		// forcibly disconnected, the next play creates a new speaker
+		u.workerLog(sp.GuildID).Warn("disconnected from voice channel")
+		delete(ps.speakers, sp.GuildID)
+		sp.cancelIdle()
+		if music := q.NowPlaying(); music != nil {
+			u.publishTrackEnded(sp, music, false, false)
+		}
+		sp.CurrentStartTime = time.Time{}
+		u.leaveStage(s, sp)
+		_ = sp.Uninitialize()
+		_ = u.UpdateNPMessage(s, sp.Player, q, -1, false, false)
+		u.publishState(sp)
+		sp.action <- domain.PlayerActionKick
+		return nil
+	}
+
+	if vs.ChannelID != sp.VoiceChannel.ID {
+		vch, err := s.Channel(vs.ChannelID)
+		if err != nil {
+			return err
+		}
+		u.workerLog(sp.GuildID).Info("moved to voice channel", "channel", vch.ID)
+		sp.VoiceChannel = vch
+		u.publishState(sp)
+	}
+	if sp.stageChannelID != "" && sp.stageChannelID != sp.VoiceChannel.ID {
+		// moved away, either here or by MoveVoiceChannel
+		u.leaveStage(s, sp)
+	}
+
+	// joining a Stage starts as an audience member, done once a speaker or after requesting to speak
+	if sp.VoiceChannel.Type == discordgo.ChannelTypeGuildStageVoice && vs.Suppress && vs.RequestToSpeakTimestamp == nil {
+		// makes REST calls, which should not hold up the other players of this shard
+		go u.joinStage(s, sp, sp.VoiceChannel.ID, sp.NPChannel.ID)
+	}
+	return nil
+}
+
+func (u *playerUseCase) UpdateIdle(s *discordgo.Session, p *domain.Player, q *domain.Queue) error {
+	ps := u.partition(p.GuildID)
+	ps.lock.RLock()
+	sp, ok := ps.speakers[p.GuildID]
+	ps.lock.RUnlock()
+	if !ok || sp.Status == domain.PlayerStatusUninitialized {
+		return domain.ErrNotPlaying
+	}
+
+	settings, err := u.settingsRepo.Get(p.GuildID)
+	if err != nil {
+		return err
+	}
+
+	listeners := util.CountListeners(s, sp.Player)
+	sp.idleLock.Lock()
+	autoPaused := sp.autoPaused
+	sp.idleLock.Unlock()
+	if listeners == 0 && settings.AutoPause && sp.Status == domain.PlayerStatusPlaying {
+		err := u.pause(sp.Player)
+		if err != nil {
+			return err
+		}
+		sp.idleLock.Lock()
+		sp.autoPaused = true
+		sp.idleLock.Unlock()
+	} else if listeners > 0 && autoPaused {
+		// playing cancels the idle countdown, which also clears autoPaused
+		err := u.Play(sp.Player)
+		if err != nil {
+			return err
+		}
+	}
+
+	u.scheduleIdle(s, sp, q)
+	return nil
+}
+
 func (u *playerUseCase) Kick(s *discordgo.Session, p *domain.Player, q *domain.Queue) error {
-	u.lock.Lock()
-	defer u.lock.Unlock()
 	if p == nil {
 		return domain.ErrNotPlaying
 	}
+	ps := u.partition(p.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
 
-	sp, ok := u.speakers[p.GuildID]
+	sp, ok := ps.speakers[p.GuildID]
 	if !ok {
 		return domain.ErrNotPlaying
 	}
-	delete(u.speakers, p.GuildID)
+	delete(ps.speakers, p.GuildID)
 
+	sp.cancelIdle()
+	if sp.Status == domain.PlayerStatusUninitialized {
+		// worker has already exited
+		return nil
+	}
+	u.leaveStage(s, sp)
 	_ = sp.Uninitialize()
 	_ = u.UpdateNPMessage(s, sp.Player, q, -1, false, false)
+	u.publishState(sp)
 	sp.action <- domain.PlayerActionKick
 	return nil
 }
 
 func (u *playerUseCase) Count() int {
-	u.lock.RLock()
-	defer u.lock.RUnlock()
+	n := 0
+	for _, ps := range u.partitions {
+		ps.lock.RLock()
+		n += len(ps.speakers)
+		ps.lock.RUnlock()
+	}
 
-	return len(u.speakers)
+	return n
 }
 
 func (u *playerUseCase) TotalPlaytime() time.Duration {
-	u.lock.RLock()
-	defer u.lock.RUnlock()
-
 	var t time.Duration
-	for _, sp := range u.speakers {
-		t += sp.playtime
+	for _, ps := range u.partitions {
+		ps.lock.RLock()
+		for _, sp := range ps.speakers {
+			t += sp.playtime
+		}
+		ps.lock.RUnlock()
 	}
 
 	return t
@@ -214,8 +481,8 @@ func (u *playerUseCase) startSpeakerWorker(s *discordgo.Session, sp *speaker, q
 		return errors.New("player uninitialized")
 	}
 
-	wlog := util.NewWorkerLogger(sp.GuildID, "SpeakerWorker")
-	wlog("starting worker")
+	wlog := u.workerLog(sp.GuildID)
+	wlog.Info("starting worker")
 
 	for {
 	statusSwitch:
@@ -224,18 +491,20 @@ func (u *playerUseCase) startSpeakerWorker(s *discordgo.Session, sp *speaker, q
 			music := q.NowPlaying()
 			if music == nil {
 				// end of queue
+				if q.Autoplay == domain.AutoplayModeOn {
+					err := u.autoplay(s, q)
+					if err == nil {
+						break statusSwitch
+					}
+					wlog.Warn("autoplay failed", "err", err)
+				}
 				sp.Status = domain.PlayerStatusStopped
 				q.Proceed()
-				err := u.UpdateNPMessage(s, sp.Player, q, -1, false, true)
-				if err != nil {
-					wlog("failed to update np message:", err)
-				}
+				u.publishState(sp)
+				u.scheduleIdle(s, sp, q)
 				break statusSwitch
 			}
-			err := u.UpdateNPMessage(s, sp.Player, q, -1, false, true)
-			if err != nil {
-				wlog("failed to update np message:", err)
-			}
+			u.bus.Publish(domain.TrackLoading{GuildID: sp.GuildID, Music: music})
 			if !music.Loaded {
 				err := u.musicRepo.Load(music)
 				if err != nil {
@@ -244,14 +513,10 @@ func (u *playerUseCase) startSpeakerWorker(s *discordgo.Session, sp *speaker, q
 						Description: fmt.Sprintf("Could not find `%s`!", music.Query),
 						Color:       common.ColorError,
 					})
-					wlog(err)
+					wlog.Error("failed to load music", "query", music.Query, "err", err)
 					q.Proceed()
 					break statusSwitch
 				}
-				err = u.UpdateNPMessage(s, sp.Player, q, -1, false, true)
-				if err != nil {
-					wlog("failed to update np message:", err)
-				}
 			}
 
 			surl, err := u.musicRepo.GetStreamURL(music)
@@ -260,24 +525,41 @@ func (u *playerUseCase) startSpeakerWorker(s *discordgo.Session, sp *speaker, q
 					Description: "Failed retrieving stream URL!",
 					Color:       common.ColorError,
 				})
-				wlog(err)
+				wlog.Error("failed to get stream url", "music", music.ID, "err", err)
 			}
 
+			var offset time.Duration
+			resumed := sp.resumeMusicID == music.ID
+			if resumed {
+				offset = sp.resumeOffset
+			} else {
+				sp.listened = 0
+			}
+			sp.resumeMusicID, sp.resumeOffset = "", 0
+			sp.streamOffset = offset
+
 			stop := make(chan bool, 1)
-			next := make(chan bool, 1)
+			next := make(chan time.Duration, 1)
+			dropped := make(chan time.Duration, 1)
 			go func() {
-				if sp.Conn != nil && sp.Conn.Ready {
-					wlog(fmt.Sprintf("play: stream url: %s", surl))
-					sp.CurrentStartTime = time.Now()
-					dgvoice.PlayAudioFile(sp.Conn, surl, stop)
+				if sp.Conn != nil && sp.Conn.Ready() {
+					wlog.Debug("play", "music", music.ID, "stream_url", surl, "offset", offset)
+					start := time.Now().Add(-offset)
+					sp.CurrentStartTime = start
+					u.bus.Publish(domain.TrackStarted{GuildID: sp.GuildID, VoiceChannelID: sp.VoiceChannel.ID, Music: music, Offset: offset, Resumed: resumed})
+					sp.Conn.Play(surl, offset, u.volume(sp.GuildID), stop)
+					offset = time.Since(start)
 					sp.CurrentStartTime = time.Time{}
-				} else {
-					wlog("stop: conn is not ready")
-					sp.Status = domain.PlayerStatusStopped
+				}
+				if sp.Conn == nil || !sp.Conn.Ready() {
+					dropped <- offset
+					return
 				}
 
-				next <- true
+				next <- offset
 			}()
+			health := time.NewTicker(healthCheckInterval)
+			timeout := time.NewTimer(music.Duration + 30*time.Second)
 
 		wait:
 			for {
@@ -285,43 +567,375 @@ func (u *playerUseCase) startSpeakerWorker(s *discordgo.Session, sp *speaker, q
 				case act := <-sp.action:
 					switch act {
 					case domain.PlayerActionSkip:
+						u.publishTrackEnded(sp, music, true, false)
 						stop <- true
 						break wait
-					case domain.PlayerActionStop:
-						stop <- true
-						err := u.UpdateNPMessage(s, sp.Player, q, -1, false, true)
-						if err != nil {
-							wlog("failed to update np message:", err)
+					case domain.PlayerActionStop, domain.PlayerActionPause:
+						if act == domain.PlayerActionPause && !sp.CurrentStartTime.IsZero() {
+							sp.resumeMusicID, sp.resumeOffset = music.ID, time.Since(sp.CurrentStartTime)
 						}
+						u.publishTrackEnded(sp, music, false, act == domain.PlayerActionPause)
+						stop <- true
+						sp.CurrentStartTime = time.Time{}
+						u.publishState(sp)
+						u.scheduleIdle(s, sp, q)
 						break wait
 					case domain.PlayerActionKick:
+						u.publishTrackEnded(sp, music, false, false)
 						stop <- true
 						return nil
 					default:
-						wlog("unknown action:", act)
+						wlog.Warn("unknown action", "action", act)
 					}
-				case <-next:
+				case elapsed := <-next:
+					sp.addPlayed(elapsed - sp.streamOffset)
+					u.bus.Publish(domain.TrackEnded{GuildID: sp.GuildID, VoiceChannelID: sp.VoiceChannel.ID, Music: music, Elapsed: elapsed, Listened: sp.listened})
 					stop <- true
 					q.Proceed()
 					break wait
-				case <-time.After(music.Duration + 30*time.Second):
+				case pos := <-dropped:
+					wlog.Warn("conn dropped", "position", pos.Round(time.Second))
+					sp.resumeMusicID, sp.resumeOffset = music.ID, pos
+					sp.addPlayed(pos - sp.streamOffset)
+					err := u.reconnect(s, sp, q, wlog)
+					if errors.Is(err, errSpeakerKicked) {
+						health.Stop()
+						timeout.Stop()
+						return nil
+					}
+					if err != nil {
+						health.Stop()
+						timeout.Stop()
+						return err
+					}
+					break wait
+				case <-health.C:
+					if sp.Conn == nil || !sp.Conn.Ready() {
+						// unblock playback so the drop gets reported
+						select {
+						case stop <- true:
+						default:
+						}
+					}
+				case <-timeout.C:
+					u.publishTrackEnded(sp, music, false, false)
 					stop <- true
-					wlog("timeout: playtime exceeded")
+					wlog.Warn("timeout: playtime exceeded")
 					break wait
 				}
 			}
-			sp.playtime += music.Duration
+			health.Stop()
+			timeout.Stop()
 
 		case domain.PlayerStatusStopped:
 			switch <-sp.action {
 			case domain.PlayerActionPlay, domain.PlayerActionSkip:
 				sp.Status = domain.PlayerStatusPlaying
+				q.Resume()
+				u.publishState(sp)
 				break statusSwitch
 			case domain.PlayerActionKick:
 				return nil
 			}
+		default:
+			// uninitialized by a kick after reconnecting, which still waits for its action to be received
+			<-sp.action
+			return nil
+		}
+	}
+}
+
+// reconnect rejoins the voice channel with exponential backoff, giving up after reconnectMaxAttempts.
+func (u *playerUseCase) reconnect(s *discordgo.Session, sp *speaker, q *domain.Queue, wlog *logger.Logger) error {
+	backoff := reconnectBaseBackoff
+	for attempt := 1; attempt <= reconnectMaxAttempts; attempt++ {
+		sp.ReconnectAttempt = attempt
+		u.publishState(sp)
+
+		timer := time.NewTimer(backoff)
+	sleep:
+		for {
+			select {
+			case act := <-sp.action:
+				if act == domain.PlayerActionKick {
+					timer.Stop()
+					return errSpeakerKicked
+				}
+			case <-timer.C:
+				break sleep
+			}
+		}
+
+		wlog.Info("reconnecting", "attempt", attempt, "max_attempts", reconnectMaxAttempts)
+		if sp.Conn != nil {
+			_ = sp.Conn.Disconnect()
+		}
+		conn, err := u.voiceRepo.Join(s, sp.VoiceChannel.GuildID, sp.VoiceChannel.ID)
+		if err == nil {
+			// joining takes a while, the speaker may have been kicked meanwhile
+			registered := u.lockSpeaker(sp, func(*speakerPartition) {
+				sp.Conn = conn
+				sp.ReconnectAttempt = 0
+			})
+			if !registered {
+				_ = conn.Disconnect()
+				return errSpeakerKicked
+			}
+			u.publishState(sp)
+			return nil
+		}
+		wlog.Warn("reconnect failed", "attempt", attempt, "err", err)
+		backoff *= 2
+	}
+
+	if !u.release(sp) {
+		return errSpeakerKicked
+	}
+	sp.ReconnectAttempt = 0
+	sp.CurrentStartTime = time.Time{}
+	u.leaveStage(s, sp)
+	_ = sp.Uninitialize()
+	err := u.UpdateNPMessage(s, sp.Player, q, -1, false, true)
+	if err != nil {
+		wlog.Error("failed to update np message", "err", err)
+	}
+	u.publishState(sp)
+	return fmt.Errorf("reconnect: gave up after %d attempts", reconnectMaxAttempts)
+}
+
+// release removes the speaker from its worker once it gives up, so the next play creates a new one.
+// False is returned if the speaker was kicked meanwhile.
+func (u *playerUseCase) release(sp *speaker) bool {
+	return u.lockSpeaker(sp, func(ps *speakerPartition) {
+		delete(ps.speakers, sp.GuildID)
+	})
+}
+
+// lockSpeaker runs fn under the partition lock if sp is still registered, returning false otherwise.
+// Actions sent while waiting for the lock are dropped, as their senders hold it and wait for the worker.
+func (u *playerUseCase) lockSpeaker(sp *speaker, fn func(ps *speakerPartition)) bool {
+	locked := make(chan bool, 1)
+	go func() {
+		ps := u.partition(sp.GuildID)
+		ps.lock.Lock()
+		defer ps.lock.Unlock()
+		ok := ps.speakers[sp.GuildID] == sp
+		if ok {
+			fn(ps)
+		}
+		locked <- ok
+	}()
+	for {
+		select {
+		case <-sp.action:
+		case ok := <-locked:
+			return ok
+		}
+	}
+}
+
+// joinStage becomes a speaker in the Stage channel, starting its Stage instance first if enabled and not yet live.
+// Without the Stage moderator permissions, the bot requests to speak instead and explains it in the NP channel.
+// It runs without the partition lock, which is only taken to record the started instance.
+func (u *playerUseCase) joinStage(s *discordgo.Session, sp *speaker, channelID, npChannelID string) {
+	wlog := u.workerLog(sp.GuildID)
+
+	settings, err := u.settingsRepo.Get(sp.GuildID)
+	if err != nil {
+		wlog.Error("failed to get settings", "err", err)
+		return
+	}
+	if settings.StageInstance {
+		if _, err := s.StageInstance(channelID); err != nil {
+			_, err = s.StageInstanceCreate(&discordgo.StageInstanceParams{
+				ChannelID: channelID,
+				Topic:     stageInstanceTopic,
+			})
+			if err == nil {
+				u.recordStage(s, sp, channelID)
+			} else {
+				wlog.Warn("failed to start stage instance", "channel", channelID, "err", err)
+			}
+		}
+	}
+
+	err = util.SetStageSpeaker(s, sp.GuildID, channelID)
+	if errors.Is(err, domain.ErrStageModerator) {
+		wlog.Info("requested to speak", "channel", channelID)
+		_, _ = s.ChannelMessageSendEmbed(npChannelID, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("I have requested to speak in <#%s>, invite me to speak to start listening!\nGive me the **Manage Channels**, **Mute Members** and **Move Members** permissions there to become a speaker on my own.", channelID),
+			Color:       common.ColorError,
+		})
+		return
+	}
+	if err != nil {
+		wlog.Warn("failed to become stage speaker", "channel", channelID, "err", err)
+	}
+}
+
+// recordStage keeps the Stage instance started in channelID to be ended when leaving,
+// or ends it right away if the speaker has left the channel meanwhile.
+func (u *playerUseCase) recordStage(s *discordgo.Session, sp *speaker, channelID string) {
+	ps := u.partition(sp.GuildID)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if ps.speakers[sp.GuildID] != sp || sp.Status == domain.PlayerStatusUninitialized || sp.VoiceChannel.ID != channelID {
+		_ = s.StageInstanceDelete(channelID)
+		return
+	}
+	sp.stageChannelID = channelID
+}
+
+// leaveStage ends the Stage instance started by the bot, if any.
+func (u *playerUseCase) leaveStage(s *discordgo.Session, sp *speaker) {
+	if sp.stageChannelID == "" {
+		return
+	}
+	err := s.StageInstanceDelete(sp.stageChannelID)
+	if err != nil {
+		u.workerLog(sp.GuildID).Warn("failed to end stage instance", "channel", sp.stageChannelID, "err", err)
+	}
+	sp.stageChannelID = ""
+}
+
+func (u *playerUseCase) publishState(sp *speaker) {
+	u.bus.Publish(domain.PlayerStateChanged{GuildID: sp.GuildID, VoiceChannelID: sp.VoiceChannel.ID, Status: sp.Status})
+}
+
+// publishTrackEnded reports a track interrupted before it finished, taking the position from its start time,
+// and accounts the time it streamed. Nothing is done if the track never started streaming or has already been reported.
+func (u *playerUseCase) publishTrackEnded(sp *speaker, music *domain.Music, skipped, paused bool) {
+	if sp.CurrentStartTime.IsZero() {
+		return
+	}
+	elapsed := time.Since(sp.CurrentStartTime)
+	sp.addPlayed(elapsed - sp.streamOffset)
+	u.bus.Publish(domain.TrackEnded{
+		GuildID:        sp.GuildID,
+		VoiceChannelID: sp.VoiceChannel.ID,
+		Music:          music,
+		Elapsed:        elapsed,
+		Listened:       sp.listened,
+		Skipped:        skipped,
+		Paused:         paused,
+	})
+}
+
+// addPlayed accounts d more streaming of the current track.
+func (sp *speaker) addPlayed(d time.Duration) {
+	sp.listened += d
+	sp.playtime += d
+}
+
+// scheduleIdle starts the idle countdown when the player is stopped or left alone, and cancels it otherwise.
+func (u *playerUseCase) scheduleIdle(s *discordgo.Session, sp *speaker, q *domain.Queue) {
+	sp.idleLock.Lock()
+	defer sp.idleLock.Unlock()
+
+	settings, err := u.settingsRepo.Get(sp.GuildID)
+	if err != nil || settings.IdleTimeout == 0 || !u.isIdle(s, sp) {
+		sp.stopIdleTimer()
+		return
+	}
+	if sp.idleTimer != nil {
+		// already counting down
+		return
+	}
+
+	sp.idleTimer = time.AfterFunc(settings.IdleTimeout, func() {
+		sp.idleLock.Lock()
+		sp.idleTimer = nil
+		idle := u.isIdle(s, sp)
+		sp.idleLock.Unlock()
+		if !idle {
+			return
+		}
+
+		u.workerLog(sp.GuildID).Info("idle: disconnecting", "timeout", settings.IdleTimeout)
+		err := u.Kick(s, sp.Player, q)
+		if err != nil {
+			return
+		}
+		_, _ = s.ChannelMessageSendEmbed(sp.NPChannel.ID, &discordgo.MessageEmbed{
+			Description: "Left the voice channel due to inactivity!",
+			Color:       common.ColorAction,
+		})
+	})
+}
+
+func (u *playerUseCase) isIdle(s *discordgo.Session, sp *speaker) bool {
+	if sp.Status == domain.PlayerStatusUninitialized {
+		return false
+	}
+	return sp.Status == domain.PlayerStatusStopped || util.CountListeners(s, sp.Player) == 0
+}
+
+func (sp *speaker) cancelIdle() {
+	sp.idleLock.Lock()
+	defer sp.idleLock.Unlock()
+
+	sp.autoPaused = false
+	sp.stopIdleTimer()
+}
+
+func (sp *speaker) stopIdleTimer() {
+	if sp.idleTimer != nil {
+		sp.idleTimer.Stop()
+		sp.idleTimer = nil
+	}
+}
+
+// volume returns the guild's playback volume as a gain factor.
+func (u *playerUseCase) volume(guildID string) float64 {
+	settings, err := u.settingsRepo.Get(guildID)
+	if err != nil {
+		return 1
+	}
+	return float64(settings.Volume) / 100
+}
+
+// autoplay appends tracks related to the recently played ones and jumps to the first of them.
+func (u *playerUseCase) autoplay(s *discordgo.Session, q *domain.Queue) error {
+	seeds := q.ActiveTracks
+	if len(seeds) > autoplaySeedSize {
+		seeds = seeds[len(seeds)-autoplaySeedSize:]
+	}
+	musics, err := u.musicRepo.GetRelated(seeds, autoplayBatchSize)
+	if err != nil {
+		return err
+	}
+
+	startPos := -1
+	for _, m := range musics {
+		played := false
+		for _, t := range q.ActiveTracks {
+			if t.SameAs(m) {
+				played = true
+				break
+			}
+		}
+		if played {
+			continue
+		}
+
+		m.ID = uuid.NewString()
+		m.QueuedAt = time.Now()
+		m.QueuedByID = s.State.User.ID
+		m.QueuedByUsername = s.State.User.Username
+		pos, err := u.queueRepo.Enqueue(q.GuildID, m)
+		if err != nil {
+			return err
+		}
+		if startPos == -1 {
+			startPos = pos
 		}
 	}
+	if startPos == -1 {
+		return domain.ErrMusicNotFound
+	}
+
+	return u.queueRepo.Jump(q.GuildID, startPos)
 }
 
 func (u *playerUseCase) UpdateNPMessage(s *discordgo.Session, p *domain.Player, q *domain.Queue, queuePage int, toggleQueue, keepLast bool) error {
@@ -337,15 +951,28 @@ func (u *playerUseCase) UpdateNPMessage(s *discordgo.Session, p *domain.Player,
 	}
 	cmps := util.BuildNPComponent(p, q)
 	if p.ShowQueue {
-		items, queuePage, err := q.GetPageItems(queuePage)
+		settings, err := u.settingsRepo.Get(p.GuildID)
+		if err != nil {
+			return err
+		}
+		items, queuePage, err := q.GetPageItems(queuePage, settings.QueuePageSize)
 		if err != nil {
 			return err
 		}
-		embs = append(embs, util.BuildQueueEmbed(p, q, items, queuePage)...)
-		cmps = append(util.BuildQueueComponent(p, q, queuePage), cmps...)
+		embs = append(embs, util.BuildQueueEmbed(p, q, items, queuePage, settings)...)
+		cmps = append(util.BuildQueueComponent(p, q, queuePage, settings), cmps...)
 	}
 	cmps = append(cmps, util.BuildCommonComponent(p, q)...)
 
+	// dedicated music channels keep a single pinned message
+	mc, err := u.musicChannelRepo.Get(p.GuildID)
+	if err != nil && !errors.Is(err, domain.ErrMusicChannelNotFound) {
+		return err
+	}
+	if mc != nil {
+		return u.updatePinnedNPMessage(s, p, mc, embs, cmps)
+	}
+
 	// get latest messageID in channel
 	npch, err := s.Channel(p.NPChannel.ID)
 	if err != nil {
@@ -369,9 +996,56 @@ func (u *playerUseCase) UpdateNPMessage(s *discordgo.Session, p *domain.Player,
 		})
 	}
 	if err != nil {
+		metrics.NPMessageErrors.Inc()
 		return err
 	}
 	p.LastNPMessageID = msg.ID
 
 	return nil
 }
+
+func (u *playerUseCase) updatePinnedNPMessage(s *discordgo.Session, p *domain.Player, mc *domain.MusicChannel, embs []*discordgo.MessageEmbed, cmps []discordgo.MessageComponent) error {
+	if p.NPChannel.ID != mc.ChannelID {
+		npch, err := s.Channel(mc.ChannelID)
+		if err != nil {
+			return err
+		}
+		if p.LastNPMessageID != "" {
+			_ = s.ChannelMessageDelete(p.NPChannel.ID, p.LastNPMessageID)
+		}
+		p.NPChannel = npch
+	}
+
+	if mc.MessageID != "" {
+		msg, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			Channel:    mc.ChannelID,
+			ID:         mc.MessageID,
+			Embeds:     embs,
+			Components: cmps,
+		})
+		if err == nil {
+			p.LastNPMessageID = msg.ID
+			return nil
+		}
+		var restErr *discordgo.RESTError
+		if !errors.As(err, &restErr) || restErr.Response == nil || restErr.Response.StatusCode != http.StatusNotFound {
+			metrics.NPMessageErrors.Inc()
+			return err
+		}
+		// message was deleted, send a new one
+	}
+
+	msg, err := s.ChannelMessageSendComplex(mc.ChannelID, &discordgo.MessageSend{
+		Embeds:     embs,
+		Components: cmps,
+	})
+	if err != nil {
+		metrics.NPMessageErrors.Inc()
+		return err
+	}
+	_ = s.ChannelMessagePin(mc.ChannelID, msg.ID)
+	p.LastNPMessageID = msg.ID
+	mc.MessageID = msg.ID
+
+	return u.musicChannelRepo.Save(mc)
+}
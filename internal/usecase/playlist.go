@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+func NewPlaylistUseCase(playlistRepo domain.PlaylistRepository) (domain.PlaylistUseCase, error) {
+	return &playlistUseCase{
+		playlistRepo: playlistRepo,
+	}, nil
+}
+
+type playlistUseCase struct {
+	playlistRepo domain.PlaylistRepository
+}
+
+var _ domain.PlaylistUseCase = (*playlistUseCase)(nil)
+
+func (u *playlistUseCase) Save(q *domain.Queue, name string, user *discordgo.User) (*domain.Playlist, error) {
+	if q == nil {
+		return nil, domain.ErrQueueNotFound
+	}
+	if q.IsEmpty() {
+		return nil, domain.ErrPlaylistEmpty
+	}
+	name, err := normalizePlaylistName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// snapshot tracks so later queue changes do not leak into the playlist
+	tracks := make([]*domain.Music, 0, len(q.ActiveTracks))
+	for _, m := range q.ActiveTracks {
+		m := *m
+		tracks = append(tracks, &m)
+	}
+
+	pl := &domain.Playlist{
+		GuildID:           q.GuildID,
+		Name:              name,
+		CreatedAt:         time.Now(),
+		CreatedByID:       user.ID,
+		CreatedByUsername: user.Username,
+		Tracks:            tracks,
+	}
+	err = u.playlistRepo.Save(pl)
+	if err != nil {
+		return nil, err
+	}
+
+	return pl, nil
+}
+
+func (u *playlistUseCase) Get(guildID, name string) (*domain.Playlist, error) {
+	name, err := normalizePlaylistName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.playlistRepo.Get(guildID, name)
+}
+
+func (u *playlistUseCase) List(guildID string) ([]*domain.Playlist, error) {
+	return u.playlistRepo.List(guildID)
+}
+
+func (u *playlistUseCase) Delete(guildID, name string) error {
+	name, err := normalizePlaylistName(name)
+	if err != nil {
+		return err
+	}
+
+	return u.playlistRepo.Delete(guildID, name)
+}
+
+func (u *playlistUseCase) Tracks(pl *domain.Playlist, user *discordgo.User) []*domain.Music {
+	musics := make([]*domain.Music, 0, len(pl.Tracks))
+	for _, t := range pl.Tracks {
+		m := *t
+		m.ID = uuid.NewString()
+		m.QueuedAt = time.Now()
+		m.QueuedByID = user.ID
+		m.QueuedByUsername = user.Username
+		musics = append(musics, &m)
+	}
+
+	return musics
+}
+
+func normalizePlaylistName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > domain.PlaylistMaxNameLength {
+		return "", domain.ErrBadFormat
+	}
+
+	return name, nil
+}
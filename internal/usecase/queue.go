@@ -2,20 +2,31 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/daystram/caroline/internal/domain"
 )
 
-func NewQueueUseCase(musicRepo domain.MusicRepository, queueRepo domain.QueueRepository) (domain.QueueUseCase, error) {
+// enqueueLoadConcurrency bounds the musics loaded at once to check them before queueing.
+const enqueueLoadConcurrency = 8
+
+func NewQueueUseCase(musicRepo domain.MusicRepository, queueRepo domain.QueueRepository, settingsRepo domain.SettingsRepository, bus domain.EventBus) (domain.QueueUseCase, error) {
 	return &queueUseCase{
-		musicRepo: musicRepo,
-		queueRepo: queueRepo,
+		musicRepo:    musicRepo,
+		queueRepo:    queueRepo,
+		settingsRepo: settingsRepo,
+		bus:          bus,
 	}, nil
 }
 
 type queueUseCase struct {
-	musicRepo domain.MusicRepository
-	queueRepo domain.QueueRepository
+	musicRepo    domain.MusicRepository
+	queueRepo    domain.QueueRepository
+	settingsRepo domain.SettingsRepository
+
+	bus domain.EventBus
 }
 
 var _ domain.QueueUseCase = (*queueUseCase)(nil)
@@ -27,6 +38,10 @@ func (u *queueUseCase) Get(guildID string) (*domain.Queue, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = u.applyDefaults(guildID)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -35,26 +50,108 @@ func (u *queueUseCase) Get(guildID string) (*domain.Queue, error) {
 	return q, nil
 }
 
-func (u *queueUseCase) Enqueue(q *domain.Queue, music *domain.Music, pos int) (int, error) {
+func (u *queueUseCase) Enqueue(q *domain.Queue, music *domain.Music, pos int) (int, bool, error) {
 	if q == nil {
-		return -1, domain.ErrQueueNotFound
+		return -1, false, domain.ErrQueueNotFound
+	}
+
+	settings, err := u.settingsRepo.Get(q.GuildID)
+	if err != nil {
+		return -1, false, err
+	}
+
+	return u.enqueue(q, music, pos, settings, true)
+}
+
+// enqueue queues music if allowed by the guild's settings, loading it first when needed and load is set.
+func (u *queueUseCase) enqueue(q *domain.Queue, music *domain.Music, pos int, settings *domain.GuildSettings, load bool) (int, bool, error) {
+	duplicate := false
+	if settings.DuplicatePolicy != domain.DuplicatePolicyAllow {
+		if load && isUnresolvedSearch(music) {
+			_ = u.musicRepo.Load(music)
+		}
+		duplicate = isDuplicate(q, music)
+		if duplicate && settings.DuplicatePolicy == domain.DuplicatePolicyReject {
+			return -1, false, domain.ErrDuplicate
+		}
+	}
+	if settings.MaxQueueLength > 0 && len(q.Upcoming()) >= settings.MaxQueueLength {
+		return -1, false, domain.ErrQueueFull
+	}
+	err := u.checkUserLimits(q, music, settings, load)
+	if err != nil {
+		return -1, false, err
+	}
+	if pos == -1 && q.Fair == domain.FairModeOn {
+		pos = fairPosition(q, music.QueuedByID)
 	}
 
 	trackNo, err := u.queueRepo.Enqueue(q.GuildID, music)
 	if err != nil {
-		return -1, err
+		return -1, false, err
 	}
 
 	if pos > -1 {
 		err = u.queueRepo.Move(q.GuildID, trackNo, pos)
 		if err != nil {
 			// TODO: remove from queue
-			return -1, err
+			return -1, false, err
 		}
 		trackNo = pos
 	}
 
-	return trackNo, nil
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
+	return trackNo, duplicate, nil
+}
+
+// EnqueueAll keeps the musics contiguous only when an explicit position is given, otherwise each is placed
+// by the queue, e.g. interleaved in fair mode.
+func (u *queueUseCase) EnqueueAll(q *domain.Queue, musics []*domain.Music, pos int) (*domain.EnqueueResult, error) {
+	settings, err := u.settingsRepo.Get(q.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	res := &domain.EnqueueResult{StartPos: -1, EndPos: -1, DuplicatePolicy: settings.DuplicatePolicy}
+	preloaded := u.preload(q, musics, settings)
+	added := make(map[*domain.Music]bool)
+	var lastErr error
+	for _, m := range musics {
+		trackNo, duplicate, err := u.enqueue(q, m, pos, settings, !preloaded[m])
+		if errors.Is(err, domain.ErrUserTrackLimit) || errors.Is(err, domain.ErrUserTimeLimit) || errors.Is(err, domain.ErrQueueFull) {
+			res.Rejected++
+			res.RejectErr = err
+			continue
+		}
+		if errors.Is(err, domain.ErrDuplicate) {
+			res.Duplicates++
+			continue
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if duplicate {
+			res.Duplicates++
+		}
+		if pos > -1 {
+			pos = trackNo + 1
+		}
+		added[m] = true
+	}
+
+	// later insertions may shift earlier ones, so resolve the positions at the end
+	for idx, m := range q.ActiveTracks {
+		if !added[m] {
+			continue
+		}
+		if res.StartPos == -1 {
+			res.StartPos = idx
+		}
+		res.EndPos = idx + 1
+		res.Positions = append(res.Positions, idx)
+	}
+
+	return res, lastErr
 }
 
 func (u *queueUseCase) Jump(q *domain.Queue, pos int) error {
@@ -66,6 +163,8 @@ func (u *queueUseCase) Jump(q *domain.Queue, pos int) error {
 	if err != nil {
 		return err
 	}
+
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
 	return nil
 }
 
@@ -78,6 +177,8 @@ func (u *queueUseCase) Move(q *domain.Queue, from, to int) error {
 	if err != nil {
 		return err
 	}
+
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
 	return nil
 }
 
@@ -90,6 +191,8 @@ func (u *queueUseCase) Remove(q *domain.Queue, pos int) error {
 	if err != nil {
 		return err
 	}
+
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
 	return nil
 }
 
@@ -103,6 +206,7 @@ func (u *queueUseCase) SetLoopMode(q *domain.Queue, mode domain.LoopMode) error
 		return err
 	}
 
+	u.bus.Publish(domain.LoopChanged{GuildID: q.GuildID, Mode: mode})
 	return nil
 }
 
@@ -116,6 +220,35 @@ func (u *queueUseCase) SetShuffleMode(q *domain.Queue, mode domain.ShuffleMode)
 		return err
 	}
 
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
+	return nil
+}
+
+func (u *queueUseCase) SetFairMode(q *domain.Queue, mode domain.FairMode) error {
+	if q == nil {
+		return domain.ErrQueueNotFound
+	}
+
+	err := u.queueRepo.SetFairMode(q.GuildID, mode)
+	if err != nil {
+		return err
+	}
+
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
+	return nil
+}
+
+func (u *queueUseCase) SetAutoplayMode(q *domain.Queue, mode domain.AutoplayMode) error {
+	if q == nil {
+		return domain.ErrQueueNotFound
+	}
+
+	err := u.queueRepo.SetAutoplayMode(q.GuildID, mode)
+	if err != nil {
+		return err
+	}
+
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
 	return nil
 }
 
@@ -128,5 +261,143 @@ func (u *queueUseCase) Clear(q *domain.Queue) error {
 	if err != nil {
 		return err
 	}
+	err = u.applyDefaults(q.GuildID)
+	if err != nil {
+		return err
+	}
+
+	u.bus.Publish(domain.QueueChanged{GuildID: q.GuildID})
+	return nil
+}
+
+func (u *queueUseCase) applyDefaults(guildID string) error {
+	settings, err := u.settingsRepo.Get(guildID)
+	if err != nil {
+		return err
+	}
+	return u.queueRepo.SetLoopMode(guildID, settings.DefaultLoop)
+}
+
+// checkUserLimits rejects music if its requester has too many tracks pending, loading it to know its duration if load is set.
+func (u *queueUseCase) checkUserLimits(q *domain.Queue, music *domain.Music, settings *domain.GuildSettings, load bool) error {
+	maxTracks, maxDuration := settings.MaxUserTracks, settings.MaxUserDuration
+	if maxTracks <= 0 && maxDuration <= 0 {
+		return nil
+	}
+
+	count, duration := 1, time.Duration(0)
+	for _, m := range q.Upcoming() {
+		if m.QueuedByID == music.QueuedByID {
+			count++
+			duration += m.Duration
+		}
+	}
+
+	if maxTracks > 0 && count > maxTracks {
+		return fmt.Errorf("%w: %d pending tracks", domain.ErrUserTrackLimit, maxTracks)
+	}
+	if maxDuration > 0 {
+		if load && duration < maxDuration && !music.Loaded {
+			// the duration is only known once loaded, tracks failing to load are let through and reported on playback
+			_ = u.musicRepo.Load(music)
+		}
+		if duration >= maxDuration || duration+music.Duration > maxDuration {
+			return fmt.Errorf("%w: %s of pending tracks", domain.ErrUserTimeLimit, maxDuration)
+		}
+	}
+
 	return nil
 }
+
+// preload concurrently loads the musics needed to check them against the guild's settings, as each may take seconds,
+// returning the ones attempted. Musics past their requester's limits are rejected without loading, so are skipped.
+func (u *queueUseCase) preload(q *domain.Queue, musics []*domain.Music, settings *domain.GuildSettings) map[*domain.Music]bool {
+	attempted := make(map[*domain.Music]bool)
+	checkDuplicates := settings.DuplicatePolicy != domain.DuplicatePolicyAllow
+	if !checkDuplicates && settings.MaxUserDuration <= 0 {
+		return attempted
+	}
+
+	var lock sync.Mutex
+	queued := len(q.Upcoming())
+	count := make(map[string]int)
+	duration := make(map[string]time.Duration)
+	for _, m := range q.Upcoming() {
+		count[m.QueuedByID]++
+		duration[m.QueuedByID] += m.Duration
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, enqueueLoadConcurrency)
+	for _, m := range musics {
+		lock.Lock()
+		full := (settings.MaxQueueLength > 0 && queued >= settings.MaxQueueLength) ||
+			(settings.MaxUserTracks > 0 && count[m.QueuedByID] >= settings.MaxUserTracks) ||
+			(settings.MaxUserDuration > 0 && duration[m.QueuedByID] >= settings.MaxUserDuration)
+		queued++
+		count[m.QueuedByID]++
+		lock.Unlock()
+		if full {
+			// durations of loads in flight are not counted yet, so a few more may be loaded than needed
+			continue
+		}
+		if m.Loaded || (settings.MaxUserDuration <= 0 && !isUnresolvedSearch(m)) {
+			lock.Lock()
+			duration[m.QueuedByID] += m.Duration
+			lock.Unlock()
+			continue
+		}
+
+		attempted[m] = true
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(m *domain.Music) {
+			defer wg.Done()
+			// tracks failing to load are let through and reported on playback
+			_ = u.musicRepo.Load(m)
+			<-sem
+
+			lock.Lock()
+			duration[m.QueuedByID] += m.Duration
+			lock.Unlock()
+		}(m)
+	}
+	wg.Wait()
+
+	return attempted
+}
+
+// isUnresolvedSearch reports whether music is a search not yet loaded, only told apart by the track it resolves to.
+func isUnresolvedSearch(music *domain.Music) bool {
+	return music.YouTubeVideoID == "" && music.SpotifyTrackID == "" && !music.Loaded
+}
+
+func isDuplicate(q *domain.Queue, music *domain.Music) bool {
+	for _, m := range q.Upcoming() {
+		if m.SameAs(music) {
+			return true
+		}
+	}
+	return false
+}
+
+// fairPosition finds the insert position that gives each requester one track per round
+// among the upcoming tracks. Returns -1 when the track should be appended at the end.
+func fairPosition(q *domain.Queue, userID string) int {
+	round := 0
+	for _, m := range q.Upcoming() {
+		if m.QueuedByID == userID {
+			round++
+		}
+	}
+
+	seen := make(map[string]int)
+	for i, m := range q.Upcoming() {
+		if seen[m.QueuedByID] > round {
+			return q.UpcomingPos() + i
+		}
+		seen[m.QueuedByID]++
+	}
+
+	return -1
+}
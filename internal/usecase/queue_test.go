@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+// newTestQueue builds a queue playing its first track, with each track requested by the given user.
+func newTestQueue(userIDs ...string) *domain.Queue {
+	q := &domain.Queue{CurrentPos: 0}
+	for _, id := range userIDs {
+		q.ActiveTracks = append(q.ActiveTracks, &domain.Music{QueuedByID: id, Loaded: true, Duration: time.Minute})
+	}
+	return q
+}
+
+func TestFairPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		queue  *domain.Queue
+		userID string
+		want   int
+	}{
+		{
+			name:   "nothing upcoming",
+			queue:  newTestQueue("a"),
+			userID: "b",
+			want:   -1,
+		},
+		{
+			name:   "new requester after first round",
+			queue:  newTestQueue("a", "a", "a", "a"),
+			userID: "b",
+			want:   2,
+		},
+		{
+			name:   "requester with most tracks appends",
+			queue:  newTestQueue("x", "a", "b", "a", "b"),
+			userID: "a",
+			want:   -1,
+		},
+		{
+			name:   "fills the round the requester is missing from",
+			queue:  newTestQueue("x", "a", "b", "a", "a"),
+			userID: "b",
+			want:   4,
+		},
+		{
+			name:   "now playing track is not a round",
+			queue:  newTestQueue("b", "a", "a"),
+			userID: "b",
+			want:   2,
+		},
+		{
+			name: "history is not counted after the queue ended",
+			queue: func() *domain.Queue {
+				q := newTestQueue("a", "a", "b")
+				q.EndPos = len(q.ActiveTracks)
+				return q
+			}(),
+			userID: "b",
+			want:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fairPosition(tt.queue, tt.userID); got != tt.want {
+				t.Errorf("fairPosition() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckUserLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		queue     *domain.Queue
+		music     *domain.Music
+		settings  domain.GuildSettings
+		want      error
+		wantLoads int
+	}{
+		{
+			name:     "no limits",
+			queue:    newTestQueue("x", "a", "a", "a"),
+			music:    &domain.Music{QueuedByID: "a"},
+			settings: domain.GuildSettings{},
+		},
+		{
+			name:     "below track limit",
+			queue:    newTestQueue("x", "a"),
+			music:    &domain.Music{QueuedByID: "a"},
+			settings: domain.GuildSettings{MaxUserTracks: 2},
+		},
+		{
+			name:     "at track limit",
+			queue:    newTestQueue("x", "a", "a"),
+			music:    &domain.Music{QueuedByID: "a"},
+			settings: domain.GuildSettings{MaxUserTracks: 2},
+			want:     domain.ErrUserTrackLimit,
+		},
+		{
+			name:     "other requesters and now playing do not count",
+			queue:    newTestQueue("a", "b", "b", "a"),
+			music:    &domain.Music{QueuedByID: "a"},
+			settings: domain.GuildSettings{MaxUserTracks: 2},
+		},
+		{
+			name:      "loaded track within duration limit",
+			queue:     newTestQueue("x", "a"),
+			music:     &domain.Music{QueuedByID: "a", Loaded: true, Duration: 2 * time.Minute},
+			settings:  domain.GuildSettings{MaxUserDuration: 3 * time.Minute},
+			wantLoads: 0,
+		},
+		{
+			name:      "unloaded track is looked up",
+			queue:     newTestQueue("x", "a"),
+			music:     &domain.Music{QueuedByID: "a", Query: "long"},
+			settings:  domain.GuildSettings{MaxUserDuration: 3 * time.Minute},
+			want:      domain.ErrUserTimeLimit,
+			wantLoads: 1,
+		},
+		{
+			name:      "duration limit already reached",
+			queue:     newTestQueue("x", "a", "a", "a"),
+			music:     &domain.Music{QueuedByID: "a", Query: "short"},
+			settings:  domain.GuildSettings{MaxUserDuration: 3 * time.Minute},
+			want:      domain.ErrUserTimeLimit,
+			wantLoads: 0,
+		},
+		{
+			name:      "track failing to load is let through",
+			queue:     newTestQueue("x", "a"),
+			music:     &domain.Music{QueuedByID: "a", Query: "missing"},
+			settings:  domain.GuildSettings{MaxUserDuration: 3 * time.Minute},
+			wantLoads: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeMusicRepository{loaded: map[string]domain.Music{
+				"short": {Duration: time.Minute},
+				"long":  {Duration: 5 * time.Minute},
+			}}
+			u := &queueUseCase{musicRepo: repo}
+			err := u.checkUserLimits(tt.queue, tt.music, &tt.settings, true)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("checkUserLimits() error = %v, want %v", err, tt.want)
+			}
+			if repo.loads != tt.wantLoads {
+				t.Errorf("checkUserLimits() loaded %d times, want %d", repo.loads, tt.wantLoads)
+			}
+		})
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	tests := []struct {
+		name  string
+		queue *domain.Queue
+		music *domain.Music
+		want  bool
+	}{
+		{
+			name:  "upcoming track",
+			queue: &domain.Queue{ActiveTracks: []*domain.Music{{YouTubeVideoID: "yt1"}, {YouTubeVideoID: "yt2"}}},
+			music: &domain.Music{YouTubeVideoID: "yt2"},
+			want:  true,
+		},
+		{
+			name:  "now playing track",
+			queue: &domain.Queue{ActiveTracks: []*domain.Music{{YouTubeVideoID: "yt1"}, {YouTubeVideoID: "yt2"}}},
+			music: &domain.Music{YouTubeVideoID: "yt1"},
+			want:  false,
+		},
+		{
+			name: "already played",
+			queue: &domain.Queue{
+				ActiveTracks: []*domain.Music{{YouTubeVideoID: "yt1"}, {YouTubeVideoID: "yt2"}},
+				CurrentPos:   1,
+			},
+			music: &domain.Music{YouTubeVideoID: "yt1"},
+			want:  false,
+		},
+		{
+			name: "resolved search",
+			queue: &domain.Queue{ActiveTracks: []*domain.Music{
+				{YouTubeVideoID: "yt1"},
+				{Query: "song", Loaded: true, URL: "https://example.com/1"},
+			}},
+			music: &domain.Music{Query: "a song", Loaded: true, URL: "https://example.com/1"},
+			want:  true,
+		},
+		{
+			name:  "empty queue",
+			queue: &domain.Queue{CurrentPos: -1},
+			music: &domain.Music{YouTubeVideoID: "yt1"},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDuplicate(tt.queue, tt.music); got != tt.want {
+				t.Errorf("isDuplicate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreload(t *testing.T) {
+	tests := []struct {
+		name      string
+		queue     *domain.Queue
+		musics    []*domain.Music
+		settings  domain.GuildSettings
+		wantLoads int
+	}{
+		{
+			name:   "nothing to check",
+			queue:  newTestQueue("x"),
+			musics: []*domain.Music{{QueuedByID: "a", Query: "short"}, {QueuedByID: "a", Query: "long"}},
+		},
+		{
+			name:  "duplicates only resolve searches",
+			queue: newTestQueue("x"),
+			musics: []*domain.Music{
+				{QueuedByID: "a", Query: "short"},
+				{QueuedByID: "a", Query: "https://youtu.be/yt1", YouTubeVideoID: "yt1"},
+				{QueuedByID: "a", Query: "long"},
+			},
+			settings:  domain.GuildSettings{DuplicatePolicy: domain.DuplicatePolicyWarn},
+			wantLoads: 2,
+		},
+		{
+			name:  "duration limit loads every track",
+			queue: newTestQueue("x"),
+			musics: []*domain.Music{
+				{QueuedByID: "a", Query: "short"},
+				{QueuedByID: "a", Query: "https://youtu.be/yt1", YouTubeVideoID: "yt1"},
+				{QueuedByID: "a", Query: "loaded", Loaded: true, Duration: time.Minute},
+			},
+			settings:  domain.GuildSettings{MaxUserDuration: time.Hour},
+			wantLoads: 2,
+		},
+		{
+			name:      "duration limit already reached",
+			queue:     newTestQueue("x", "a", "a", "a"),
+			musics:    []*domain.Music{{QueuedByID: "a", Query: "short"}, {QueuedByID: "b", Query: "short"}},
+			settings:  domain.GuildSettings{MaxUserDuration: 3 * time.Minute},
+			wantLoads: 1,
+		},
+		{
+			name:      "past track limit",
+			queue:     newTestQueue("x", "a"),
+			musics:    []*domain.Music{{QueuedByID: "a", Query: "short"}, {QueuedByID: "a", Query: "long"}, {QueuedByID: "a", Query: "short"}},
+			settings:  domain.GuildSettings{MaxUserTracks: 2, DuplicatePolicy: domain.DuplicatePolicyReject},
+			wantLoads: 1,
+		},
+		{
+			name:      "queue full",
+			queue:     newTestQueue("x", "b", "c"),
+			musics:    []*domain.Music{{QueuedByID: "a", Query: "short"}},
+			settings:  domain.GuildSettings{MaxQueueLength: 2, DuplicatePolicy: domain.DuplicatePolicyReject},
+			wantLoads: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeMusicRepository{loaded: map[string]domain.Music{
+				"short": {Duration: time.Minute},
+				"long":  {Duration: 5 * time.Minute},
+			}}
+			u := &queueUseCase{musicRepo: repo}
+			attempted := u.preload(tt.queue, tt.musics, &tt.settings)
+			if repo.loads != tt.wantLoads {
+				t.Errorf("preload() loaded %d times, want %d", repo.loads, tt.wantLoads)
+			}
+			if len(attempted) != tt.wantLoads {
+				t.Errorf("preload() attempted %d musics, want %d", len(attempted), tt.wantLoads)
+			}
+		})
+	}
+}
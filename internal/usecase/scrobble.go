@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/metrics"
+)
+
+const (
+	// https://www.last.fm/api/scrobbling#when-is-a-scrobble-a-scrobble
+	scrobbleMinDuration     = 30 * time.Second
+	scrobbleMaxRequiredPlay = 4 * time.Minute
+)
+
+func NewScrobbleUseCase(scrobbleRepo domain.ScrobbleRepository, clients map[domain.ScrobbleService]domain.ScrobbleClient, log *logger.Logger) (domain.ScrobbleUseCase, error) {
+	return &scrobbleUseCase{
+		scrobbleRepo: scrobbleRepo,
+		clients:      clients,
+		log:          log.With("component", "scrobble"),
+		pending:      make(map[string]string),
+	}, nil
+}
+
+type scrobbleUseCase struct {
+	scrobbleRepo domain.ScrobbleRepository
+	clients      map[domain.ScrobbleService]domain.ScrobbleClient
+
+	log *logger.Logger
+
+	// pending holds auth tokens awaiting authorization, keyed by user and service
+	pending map[string]string
+	lock    sync.Mutex
+}
+
+var _ domain.ScrobbleUseCase = (*scrobbleUseCase)(nil)
+
+func (u *scrobbleUseCase) Enabled(service domain.ScrobbleService) bool {
+	_, ok := u.clients[service]
+	return ok
+}
+
+func (u *scrobbleUseCase) Authorize(userID string, service domain.ScrobbleService) (string, error) {
+	c, ok := u.clients[service]
+	if !ok {
+		return "", domain.ErrScrobbleDisabled
+	}
+
+	token, authURL, err := c.AuthToken()
+	if err != nil {
+		return "", err
+	}
+	u.lock.Lock()
+	u.pending[userID+"/"+string(service)] = token
+	u.lock.Unlock()
+
+	return authURL, nil
+}
+
+func (u *scrobbleUseCase) Link(userID string, service domain.ScrobbleService, token string) (*domain.ScrobbleAccount, error) {
+	c, ok := u.clients[service]
+	if !ok {
+		return nil, domain.ErrScrobbleDisabled
+	}
+
+	key := userID + "/" + string(service)
+	if token == "" {
+		u.lock.Lock()
+		token = u.pending[key]
+		u.lock.Unlock()
+		if token == "" {
+			return nil, domain.ErrScrobbleUnauthorized
+		}
+	}
+	username, sessionKey, err := c.Session(token)
+	if err != nil {
+		return nil, err
+	}
+	u.lock.Lock()
+	delete(u.pending, key)
+	u.lock.Unlock()
+
+	account := &domain.ScrobbleAccount{
+		UserID:   userID,
+		Service:  service,
+		Username: username,
+		Key:      sessionKey,
+		LinkedAt: time.Now(),
+	}
+	err = u.scrobbleRepo.Save(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (u *scrobbleUseCase) Unlink(userID string, service domain.ScrobbleService) (*domain.ScrobbleAccount, error) {
+	accounts, err := u.scrobbleRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range accounts {
+		if a.Service != service {
+			continue
+		}
+		err = u.scrobbleRepo.Delete(userID, service)
+		if err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+
+	return nil, domain.ErrScrobbleNotFound
+}
+
+func (u *scrobbleUseCase) List(userID string) ([]*domain.ScrobbleAccount, error) {
+	return u.scrobbleRepo.List(userID)
+}
+
+func (u *scrobbleUseCase) NowPlaying(userIDs []string, music *domain.Music) {
+	s := newScrobble(music, time.Now())
+	if s == nil {
+		return
+	}
+	u.submit(userIDs, "now_playing", func(c domain.ScrobbleClient, key string) error {
+		return c.NowPlaying(key, s)
+	})
+}
+
+func (u *scrobbleUseCase) Scrobble(userIDs []string, music *domain.Music, startedAt time.Time, listened time.Duration) {
+	s := newScrobble(music, startedAt)
+	if s == nil || !scrobbleEligible(s.Duration, listened) {
+		return
+	}
+	u.submit(userIDs, "scrobble", func(c domain.ScrobbleClient, key string) error {
+		return c.Scrobble(key, s)
+	})
+}
+
+// submit calls fn for every linked account of the users, each in the background.
+func (u *scrobbleUseCase) submit(userIDs []string, op string, fn func(c domain.ScrobbleClient, key string) error) {
+	for _, userID := range userIDs {
+		accounts, err := u.scrobbleRepo.List(userID)
+		if err != nil {
+			u.log.Error("failed to list accounts", "user", userID, "err", err)
+			continue
+		}
+		for _, a := range accounts {
+			c, ok := u.clients[a.Service]
+			if !ok {
+				continue
+			}
+			go func(a *domain.ScrobbleAccount) {
+				err := fn(c, a.Key)
+				if err != nil {
+					metrics.Scrobbles.Inc(string(a.Service), op, "failed")
+					level := logger.LevelError
+					if errors.Is(err, domain.ErrScrobbleUnauthorized) {
+						// the user revoked access, nothing to do until they link again
+						level = logger.LevelWarn
+					}
+					u.log.Log(level, "submission failed", "op", op, "user", a.UserID, "service", string(a.Service), "err", err)
+					return
+				}
+				metrics.Scrobbles.Inc(string(a.Service), op, "ok")
+			}(a)
+		}
+	}
+}
+
+// newScrobble returns nil for musics whose artist and track are not known.
+func newScrobble(music *domain.Music, startedAt time.Time) *domain.Scrobble {
+	if music == nil || !music.Loaded || music.Artist == "" || music.Track == "" {
+		return nil
+	}
+	return &domain.Scrobble{
+		Artist:    music.Artist,
+		Track:     music.Track,
+		Duration:  music.Duration,
+		URL:       music.URL,
+		StartedAt: startedAt,
+	}
+}
+
+// scrobbleEligible applies the services' rule: tracks longer than 30 seconds count once
+// played for half their duration or 4 minutes, whichever comes first.
+func scrobbleEligible(duration, listened time.Duration) bool {
+	if duration <= scrobbleMinDuration {
+		return false
+	}
+	required := duration / 2
+	if required > scrobbleMaxRequiredPlay {
+		required = scrobbleMaxRequiredPlay
+	}
+	return listened >= required
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScrobbleEligible(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		elapsed  time.Duration
+		want     bool
+	}{
+		{name: "too short", duration: 30 * time.Second, elapsed: 30 * time.Second, want: false},
+		{name: "unknown duration", duration: 0, elapsed: time.Minute, want: false},
+		{name: "half played", duration: 3 * time.Minute, elapsed: 90 * time.Second, want: true},
+		{name: "under half played", duration: 3 * time.Minute, elapsed: 89 * time.Second, want: false},
+		{name: "just long enough", duration: 31 * time.Second, elapsed: 16 * time.Second, want: true},
+		{name: "long track capped", duration: time.Hour, elapsed: 4 * time.Minute, want: true},
+		{name: "long track under cap", duration: time.Hour, elapsed: 4*time.Minute - time.Second, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scrobbleEligible(tt.duration, tt.elapsed); got != tt.want {
+				t.Errorf("scrobbleEligible(%s, %s) = %v, want %v", tt.duration, tt.elapsed, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"github.com/daystram/caroline/internal/domain"
+)
+
+func NewSettingsUseCase(settingsRepo domain.SettingsRepository) (domain.SettingsUseCase, error) {
+	return &settingsUseCase{
+		settingsRepo: settingsRepo,
+	}, nil
+}
+
+type settingsUseCase struct {
+	settingsRepo domain.SettingsRepository
+}
+
+var _ domain.SettingsUseCase = (*settingsUseCase)(nil)
+
+func (u *settingsUseCase) Get(guildID string) (*domain.GuildSettings, error) {
+	return u.settingsRepo.Get(guildID)
+}
+
+func (u *settingsUseCase) Set(guildID string, key domain.SettingKey, value string) (*domain.GuildSettings, error) {
+	g, err := u.settingsRepo.Get(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate before persisting
+	err = g.Apply(key, value)
+	if err != nil {
+		return nil, err
+	}
+	err = u.settingsRepo.Set(guildID, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (u *settingsUseCase) Reset(guildID string, key domain.SettingKey) (*domain.GuildSettings, error) {
+	if !isSettingKey(key) {
+		return nil, domain.ErrSettingNotFound
+	}
+
+	err := u.settingsRepo.Reset(guildID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.settingsRepo.Get(guildID)
+}
+
+func (u *settingsUseCase) ResetAll(guildID string) (*domain.GuildSettings, error) {
+	err := u.settingsRepo.ResetAll(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.settingsRepo.Get(guildID)
+}
+
+func isSettingKey(key domain.SettingKey) bool {
+	for _, k := range domain.SettingKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+
+	"github.com/daystram/caroline/internal/domain"
+	"github.com/daystram/caroline/internal/logger"
+	"github.com/daystram/caroline/internal/metrics"
+)
+
+const (
+	webhookMaxPerGuild   = 5
+	webhookIDLength      = 8
+	webhookSecretLength  = 32
+	webhookTimeout       = 10 * time.Second
+	webhookMaxAttempts   = 4
+	webhookBaseBackoff   = time.Second
+	webhookUserAgent     = "caroline (+https://github.com/daystram/caroline)"
+	webhookSignatureAlgo = "sha256"
+)
+
+var errWebhookDestination = errors.New("destination address not allowed")
+
+type webhookPayload struct {
+	Event     domain.WebhookEvent `json:"event"`
+	Timestamp string              `json:"timestamp"`
+	Guild     webhookGuild        `json:"guild"`
+	Music     webhookMusic        `json:"music"`
+	Position  float64             `json:"position"` // in seconds
+}
+
+type webhookGuild struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type webhookMusic struct {
+	ID               string  `json:"id"`
+	Title            string  `json:"title,omitempty"`
+	Query            string  `json:"query"`
+	URL              string  `json:"url,omitempty"`
+	Thumbnail        string  `json:"thumbnail,omitempty"`
+	Duration         float64 `json:"duration,omitempty"` // in seconds
+	Source           string  `json:"source"`
+	SpotifyTrackID   string  `json:"spotify_track_id,omitempty"`
+	YouTubeVideoID   string  `json:"youtube_video_id,omitempty"`
+	QueuedByID       string  `json:"queued_by_id"`
+	QueuedByUsername string  `json:"queued_by_username"`
+	QueuedAt         string  `json:"queued_at"`
+}
+
+func NewWebhookUseCase(webhookRepo domain.WebhookRepository, log *logger.Logger) (domain.WebhookUseCase, error) {
+	return &webhookUseCase{
+		webhookRepo: webhookRepo,
+		client:      newWebhookClient(),
+		log:         log.With("component", "webhooks"),
+	}, nil
+}
+
+type webhookUseCase struct {
+	webhookRepo domain.WebhookRepository
+
+	client *http.Client
+	log    *logger.Logger
+}
+
+var _ domain.WebhookUseCase = (*webhookUseCase)(nil)
+
+func (u *webhookUseCase) Add(guildID, rawURL string, user *discordgo.User) (*domain.Webhook, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, domain.ErrBadFormat
+	}
+	if ip := net.ParseIP(target.Hostname()); ip != nil && !webhookAllowedIP(ip) {
+		return nil, domain.ErrBadFormat
+	}
+	whs, err := u.webhookRepo.List(guildID)
+	if err != nil {
+		return nil, err
+	}
+	if len(whs) >= webhookMaxPerGuild {
+		return nil, fmt.Errorf("%w: %d webhooks", domain.ErrWebhookLimit, webhookMaxPerGuild)
+	}
+
+	secret := make([]byte, webhookSecretLength)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+	wh := &domain.Webhook{
+		ID:          uuid.NewString()[:webhookIDLength],
+		GuildID:     guildID,
+		URL:         target.String(),
+		Secret:      hex.EncodeToString(secret),
+		CreatedAt:   time.Now(),
+		CreatedByID: user.ID,
+	}
+	err = u.webhookRepo.Save(wh)
+	if err != nil {
+		return nil, err
+	}
+
+	return wh, nil
+}
+
+func (u *webhookUseCase) List(guildID string) ([]*domain.Webhook, error) {
+	return u.webhookRepo.List(guildID)
+}
+
+func (u *webhookUseCase) Remove(guildID, id string) (*domain.Webhook, error) {
+	whs, err := u.webhookRepo.List(guildID)
+	if err != nil {
+		return nil, err
+	}
+	for _, wh := range whs {
+		if wh.ID != id {
+			continue
+		}
+		err = u.webhookRepo.Delete(guildID, id)
+		if err != nil {
+			return nil, err
+		}
+		return wh, nil
+	}
+
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (u *webhookUseCase) Deliver(d *domain.WebhookDelivery) {
+	whs, err := u.webhookRepo.List(d.GuildID)
+	if err != nil {
+		u.log.Error("failed to list webhooks", "guild", d.GuildID, "err", err)
+		return
+	}
+	if len(whs) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(newWebhookPayload(d))
+	if err != nil {
+		u.log.Error("failed to encode webhook payload", "guild", d.GuildID, "err", err)
+		return
+	}
+	for _, wh := range whs {
+		go u.send(wh, d.Event, uuid.NewString(), body)
+	}
+}
+
+// send posts body to the webhook, retrying with exponential backoff on network errors,
+// rate limits and server errors. Other client errors are not retried.
+func (u *webhookUseCase) send(wh *domain.Webhook, event domain.WebhookEvent, deliveryID string, body []byte) {
+	wlog := u.log.With("guild", wh.GuildID, "webhook", wh.ID, "event", string(event), "delivery", deliveryID)
+
+	backoff := webhookBaseBackoff
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		retry, err := u.post(wh, event, deliveryID, body)
+		if err == nil {
+			metrics.WebhookDeliveries.Inc("ok")
+			wlog.Debug("webhook delivered", "attempt", attempt)
+			return
+		}
+		if !retry || attempt == webhookMaxAttempts {
+			metrics.WebhookDeliveries.Inc("failed")
+			wlog.Warn("webhook delivery failed", "attempt", attempt, "err", err)
+			return
+		}
+		wlog.Debug("webhook delivery failed, retrying", "attempt", attempt, "backoff", backoff, "err", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (u *webhookUseCase) post(wh *domain.Webhook, event domain.WebhookEvent, deliveryID string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, withoutURL(err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set("X-Caroline-Event", string(event))
+	req.Header.Set("X-Caroline-Delivery", deliveryID)
+	req.Header.Set("X-Caroline-Timestamp", timestamp)
+	req.Header.Set("X-Caroline-Signature", webhookSignatureAlgo+"="+signWebhook(wh.Secret, timestamp, body))
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return !errors.Is(err, errWebhookDestination), withoutURL(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	return retry, fmt.Errorf("unexpected status: %s", resp.Status)
+}
+
+// withoutURL strips the request URL from err, as it is logged and webhook URLs carry their credentials,
+// in the path for Discord and Slack webhooks.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// newWebhookClient returns a client that only connects to public addresses and does not follow redirects,
+// so guild-supplied URLs cannot reach the host or its internal network. The address is checked when
+// connecting, after name resolution, which also covers hostnames resolving to internal addresses.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !webhookAllowedIP(ip) {
+				return fmt.Errorf("%w: %s", errWebhookDestination, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // the proxy address would be checked instead of the destination
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   webhookTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			// the redirect response is reported as an unexpected status
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func webhookAllowedIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast()
+}
+
+// signWebhook signs the timestamp and body so receivers can verify the sender and reject replays.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookPayload(d *domain.WebhookDelivery) *webhookPayload {
+	m := d.Music
+	payload := &webhookPayload{
+		Event:     d.Event,
+		Timestamp: d.Time.Format(time.RFC3339),
+		Guild: webhookGuild{
+			ID:   d.GuildID,
+			Name: d.GuildName,
+		},
+		Music: webhookMusic{
+			ID:               m.ID,
+			Query:            m.Query,
+			Source:           m.Source.String(),
+			SpotifyTrackID:   m.SpotifyTrackID,
+			YouTubeVideoID:   m.YouTubeVideoID,
+			QueuedByID:       m.QueuedByID,
+			QueuedByUsername: m.QueuedByUsername,
+			QueuedAt:         m.QueuedAt.Format(time.RFC3339),
+		},
+		Position: d.Position.Seconds(),
+	}
+	if m.Loaded {
+		payload.Music.Title = m.Title
+		payload.Music.URL = m.URL
+		payload.Music.Thumbnail = m.Thumbnail
+		payload.Music.Duration = m.Duration.Seconds()
+	}
+
+	return payload
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSignWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{
+			name:      "payload",
+			secret:    "secret",
+			timestamp: "1700000000",
+			body:      `{"event":"track_start"}`,
+			want:      "d4ddfb9b2694acf2d3d1c47ef6a4a9b39d95898960edd6d3795742ec466d19d8",
+		},
+		{
+			name:      "different secret",
+			secret:    "other",
+			timestamp: "1700000000",
+			body:      `{"event":"track_start"}`,
+			want:      "56aeb8f6e71f30d22e329f2baa5fd782f365fc78a6e578261bb79a6a132aedd4",
+		},
+		{
+			name:      "replayed at another time",
+			secret:    "secret",
+			timestamp: "1700000001",
+			body:      `{"event":"track_start"}`,
+			want:      "e1257334a65b79f8cd0b3a69a98d4ca05cb07088a9a503e6fac67e3973805560",
+		},
+		{
+			name:      "empty body",
+			secret:    "secret",
+			timestamp: "1700000000",
+			want:      "4bc5f74d868b97888288889c5d9d65df02526f94c1592a79fdf4fe8b26e311e5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := signWebhook(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("signWebhook() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookAllowedIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.216.34", want: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "10.0.0.1", want: false},
+		{ip: "172.16.5.4", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "fd00::1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "::", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "224.0.0.1", want: false},
+		{ip: "ff01::1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := webhookAllowedIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("webhookAllowedIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutURL(t *testing.T) {
+	const secretURL = "https://discord.com/api/webhooks/1/token"
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "request error",
+			err:  &url.Error{Op: "Post", URL: secretURL, Err: fmt.Errorf("%w: 127.0.0.1", errWebhookDestination)},
+			want: errWebhookDestination,
+		},
+		{
+			name: "other error",
+			err:  errors.New("unexpected status: 404 Not Found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withoutURL(tt.err)
+			if strings.Contains(got.Error(), secretURL) {
+				t.Errorf("withoutURL() = %q, contains the URL", got)
+			}
+			if tt.want != nil && !errors.Is(got, tt.want) {
+				t.Errorf("withoutURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
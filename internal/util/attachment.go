@@ -0,0 +1,32 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	attachmentTimeout = 10 * time.Second
+)
+
+func DownloadAttachment(a *discordgo.MessageAttachment, limit int64) ([]byte, error) {
+	if int64(a.Size) > limit {
+		return nil, fmt.Errorf("attachment too large: %d bytes", a.Size)
+	}
+
+	client := http.Client{Timeout: attachmentTimeout}
+	resp, err := client.Get(a.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/daystram/dgvoice"
+)
+
+const (
+	AudioChannels  = 2
+	AudioFrameRate = 48000
+	AudioFrameSize = 960
+)
+
+// PlayAudioFile works like dgvoice.PlayAudioFile, but starts playback at the given offset and volume.
+func PlayAudioFile(v *discordgo.VoiceConnection, filename string, offset time.Duration, volume float64, stop <-chan bool) {
+	pcm, err := DecodeAudioFile(filename, offset, volume)
+	if err != nil {
+		dgvoice.OnError("RunStart Error", err)
+		return
+	}
+
+	err = v.Speaking(true)
+	if err != nil {
+		dgvoice.OnError("Couldn't set speaking", err)
+	}
+	defer func() {
+		err := v.Speaking(false)
+		if err != nil {
+			dgvoice.OnError("Couldn't stop speaking", err)
+		}
+	}()
+
+	send := make(chan []int16, 2)
+	defer close(send)
+
+	done := make(chan bool, 1)
+	go func() {
+		dgvoice.SendPCM(v, send)
+		done <- true
+	}()
+
+	for {
+		audiobuf, err := ReadAudioFrame(pcm)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return
+		}
+		if err != nil {
+			dgvoice.OnError("Error reading from ffmpeg stdout", err)
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		case <-stop:
+			return
+		case send <- audiobuf:
+		}
+	}
+}
+
+// DecodeAudioFile decodes a file or stream URL with ffmpeg into raw PCM, starting at the given offset and volume.
+func DecodeAudioFile(filename string, offset time.Duration, volume float64) (io.Reader, error) {
+	args := []string{
+		"-ss", fmt.Sprintf("%.3f", offset.Seconds()),
+		"-i", filename,
+	}
+	if volume != 1 {
+		args = append(args, "-af", fmt.Sprintf("volume=%.2f", volume))
+	}
+	args = append(args,
+		"-f", "s16le",
+		"-ar", strconv.Itoa(AudioFrameRate),
+		"-ac", strconv.Itoa(AudioChannels),
+		"pipe:1",
+	)
+	run := exec.Command("ffmpeg", args...)
+	var ffmpegbuf bytes.Buffer
+	run.Stdout = &ffmpegbuf
+
+	err := run.Run() // blocking
+	if err != nil {
+		return nil, err
+	}
+	return &ffmpegbuf, nil
+}
+
+// ReadAudioFrame reads a single frame of PCM decoded by DecodeAudioFile.
+func ReadAudioFrame(pcm io.Reader) ([]int16, error) {
+	audiobuf := make([]int16, AudioFrameSize*AudioChannels)
+	err := binary.Read(pcm, binary.LittleEndian, &audiobuf)
+	return audiobuf, err
+}
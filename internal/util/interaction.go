@@ -2,6 +2,7 @@ package util
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -14,12 +15,36 @@ func InteractionName(i *discordgo.InteractionCreate) string {
 	case discordgo.InteractionApplicationCommand:
 		return i.ApplicationCommandData().Name
 	case discordgo.InteractionMessageComponent:
-		return i.MessageComponentData().CustomID
+		name, _ := splitComponentID(i.MessageComponentData().CustomID)
+		return name
 	default:
 		return ""
 	}
 }
 
+// InteractionArg returns the argument of a component built with ComponentID, empty if there is none.
+func InteractionArg(i *discordgo.InteractionCreate) string {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return ""
+	}
+	_, arg := splitComponentID(i.MessageComponentData().CustomID)
+	return arg
+}
+
+// ComponentID appends arg to a component ID, such as the track a button acts on.
+func ComponentID(id, arg string) string {
+	return id + ":" + arg
+}
+
+// splitComponentID splits a component ID of the form <component>:<action>[:<arg>].
+func splitComponentID(customID string) (string, string) {
+	parts := strings.SplitN(customID, ":", 3)
+	if len(parts) < 3 {
+		return customID, ""
+	}
+	return parts[0] + ":" + parts[1], parts[2]
+}
+
 func GetUserVS(s *discordgo.Session, i *discordgo.InteractionCreate, must bool, msg string) (*discordgo.VoiceState, error) {
 	vs, err := s.State.VoiceState(i.GuildID, i.Member.User.ID)
 	if must && errors.Is(err, discordgo.ErrStateNotFound) {
@@ -50,3 +75,48 @@ func IsPlayerReady(p *domain.Player) bool {
 func IsSameVC(p *domain.Player, vs *discordgo.VoiceState) bool {
 	return p != nil && vs != nil && p.VoiceChannel.ID == vs.ChannelID
 }
+
+func CountListeners(s *discordgo.Session, p *domain.Player) int {
+	return len(ListenerIDs(s, p.GuildID, p.VoiceChannel.ID))
+}
+
+// ListenerIDs returns the users in a voice channel, excluding bots.
+func ListenerIDs(s *discordgo.Session, guildID, channelID string) []string {
+	g, err := s.State.Guild(guildID)
+	if err != nil {
+		return nil
+	}
+
+	userIDs := make([]string, 0)
+	s.State.RLock()
+	for _, vs := range g.VoiceStates {
+		if vs.ChannelID == channelID && vs.UserID != s.State.User.ID {
+			userIDs = append(userIDs, vs.UserID)
+		}
+	}
+	s.State.RUnlock()
+
+	listeners := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if m, err := s.State.Member(guildID, userID); err == nil && m.User != nil && m.User.Bot {
+			continue
+		}
+		listeners = append(listeners, userID)
+	}
+
+	return listeners
+}
+
+func BuildErrorResponse(msg string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Description: msg,
+					Color:       common.ColorError,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestSplitComponentID(t *testing.T) {
+	tests := []struct {
+		customID string
+		wantName string
+		wantArg  string
+	}{
+		{customID: "np_component:next", wantName: "np_component:next"},
+		{customID: "vote_component:skip:5f1c", wantName: "vote_component:skip", wantArg: "5f1c"},
+		{customID: "vote_component:skip:a:b", wantName: "vote_component:skip", wantArg: "a:b"},
+		{customID: ComponentID("vote_component:skip", "5f1c"), wantName: "vote_component:skip", wantArg: "5f1c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.customID, func(t *testing.T) {
+			name, arg := splitComponentID(tt.customID)
+			if name != tt.wantName || arg != tt.wantArg {
+				t.Errorf("splitComponentID() = %q, %q, want %q, %q", name, arg, tt.wantName, tt.wantArg)
+			}
+		})
+	}
+}
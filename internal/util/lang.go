@@ -7,3 +7,12 @@ func Plural(w string, n int) string {
 
 	return w + "s"
 }
+
+// Truncate shortens s to at most n characters, ending it with an ellipsis when cut.
+func Truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -64,6 +64,10 @@ func BuildNPEmbed(s *discordgo.Session, p *domain.Player, q *domain.Queue) ([]*d
 		if p.Status == domain.PlayerStatusPlaying {
 			color = common.ColorPlayerPlaying
 			title = "Now Playing"
+			if music.Source == domain.MusicSourceAutoplay {
+				color = common.ColorPlayerAutoplay
+				title = "Now Autoplaying"
+			}
 		} else {
 			color = common.ColorPlayerStopped
 			title = "Stopped"
@@ -75,6 +79,10 @@ func BuildNPEmbed(s *discordgo.Session, p *domain.Player, q *domain.Queue) ([]*d
 			URL: music.Thumbnail,
 		}
 	}
+	if p.ReconnectAttempt > 0 {
+		color = common.ColorPlayerLoading
+		title = fmt.Sprintf("Reconnecting (attempt %d)", p.ReconnectAttempt)
+	}
 
 	return []*discordgo.MessageEmbed{
 		{
@@ -200,12 +208,42 @@ func BuildCommonComponent(p *domain.Player, q *domain.Queue) []discordgo.Message
 		toggleShuffleBtn.Style = discordgo.SuccessButton
 	}
 
+	toggleFairBtn := discordgo.Button{
+		Disabled: p.Status == domain.PlayerStatusUninitialized || q.IsEmpty(),
+		CustomID: common.CommonComponentToggleFairID,
+	}
+	switch q.Fair {
+	case domain.FairModeOff:
+		toggleFairBtn.Label = "Fair off"
+		toggleFairBtn.Style = discordgo.SecondaryButton
+	case domain.FairModeOn:
+		toggleFairBtn.Emoji = discordgo.ComponentEmoji{Name: "⚖️"}
+		toggleFairBtn.Label = "Fair on"
+		toggleFairBtn.Style = discordgo.SuccessButton
+	}
+
+	toggleAutoplayBtn := discordgo.Button{
+		Disabled: p.Status == domain.PlayerStatusUninitialized || q.IsEmpty(),
+		CustomID: common.CommonComponentToggleAutoplayID,
+	}
+	switch q.Autoplay {
+	case domain.AutoplayModeOff:
+		toggleAutoplayBtn.Label = "Autoplay off"
+		toggleAutoplayBtn.Style = discordgo.SecondaryButton
+	case domain.AutoplayModeOn:
+		toggleAutoplayBtn.Emoji = discordgo.ComponentEmoji{Name: "📻"}
+		toggleAutoplayBtn.Label = "Autoplay on"
+		toggleAutoplayBtn.Style = discordgo.SuccessButton
+	}
+
 	return []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
 				toggleQueueBtn,
 				toggleLoopBtn,
 				toggleShuffleBtn,
+				toggleFairBtn,
+				toggleAutoplayBtn,
 			},
 		},
 	}
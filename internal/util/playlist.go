@@ -0,0 +1,62 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+)
+
+func BuildPlaylistEmbed(pl *domain.Playlist, limit int) []*discordgo.MessageEmbed {
+	items := pl.Tracks
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	builder := strings.Builder{}
+	indexPadding := len(strconv.Itoa(len(items)))
+	for i, music := range items {
+		title := music.Query
+		if music.Loaded {
+			title = music.Title
+		}
+		if len(title) > queueMaxTitleLength {
+			title = title[:queueMaxTitleLength-3] + "..."
+		}
+		builder.WriteString(fmt.Sprintf("```py\n  %*d  %-*s\n```", indexPadding, i+1, queueMaxTitleLength, title))
+	}
+	if rest := len(pl.Tracks) - len(items); rest > 0 {
+		builder.WriteString(fmt.Sprintf("_and %d more %s_", rest, Plural("track", rest)))
+	}
+
+	return []*discordgo.MessageEmbed{
+		{
+			Title:       fmt.Sprintf("Playlist: %s", pl.Name),
+			Description: builder.String(),
+			Color:       common.ColorQueue,
+			Fields: []*discordgo.MessageEmbedField{
+				{
+					Name:   "Size",
+					Value:  fmt.Sprintf("%d %s", len(pl.Tracks), Plural("track", len(pl.Tracks))),
+					Inline: true,
+				},
+				{
+					Name:   "Duration",
+					Value:  pl.Duration().Round(time.Second).String(),
+					Inline: true,
+				},
+				{
+					Name:   "Saved By",
+					Value:  pl.CreatedByUsername,
+					Inline: true,
+				},
+			},
+			Timestamp: pl.CreatedAt.Format(time.RFC3339),
+		},
+	}
+}
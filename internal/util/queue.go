@@ -18,13 +18,13 @@ const (
 	queueMaxTitleLength = 50
 )
 
-func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, page int) []*discordgo.MessageEmbed {
+func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, page int, g *domain.GuildSettings) []*discordgo.MessageEmbed {
 	if len(items) == 0 {
 		return []*discordgo.MessageEmbed{
 			{
 				Title:       "Queue",
 				Description: fmt.Sprintf("```py\n   [ ] %-*s\n```", queueMaxTitleLength, "-- Empty --"),
-				Color:       common.ColorQueue,
+				Color:       g.AccentColor(common.ColorQueue),
 			},
 		}
 	}
@@ -34,7 +34,7 @@ func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, p
 		music := *music
 		builder.WriteString("```py\n")
 
-		i += page * domain.QueuePageSize
+		i += page * g.QueuePageSize
 		if i == q.CurrentPos {
 			if p.Status == domain.PlayerStatusPlaying {
 				builder.WriteString(">>")
@@ -46,7 +46,7 @@ func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, p
 		}
 
 		var indexPadding int
-		if x, y := len(strconv.Itoa((page+1)*domain.QueuePageSize)), len(q.ActiveTracks); x < y {
+		if x, y := len(strconv.Itoa((page+1)*g.QueuePageSize)), len(q.ActiveTracks); x < y {
 			indexPadding = x
 		} else {
 			indexPadding = y
@@ -55,6 +55,9 @@ func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, p
 		if music.Loaded {
 			title = music.Title
 		}
+		if music.Source == domain.MusicSourceAutoplay {
+			title = "(auto) " + title
+		}
 		if len(title) > queueMaxTitleLength {
 			title = title[:queueMaxTitleLength-3] + "..."
 		}
@@ -70,7 +73,7 @@ func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, p
 		{
 			Title:       "Queue",
 			Description: builder.String(),
-			Color:       common.ColorQueue,
+			Color:       g.AccentColor(common.ColorQueue),
 			Fields: []*discordgo.MessageEmbedField{
 				{
 					Name:   "Size",
@@ -79,7 +82,7 @@ func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, p
 				},
 				{
 					Name:   "Page",
-					Value:  fmt.Sprintf("%d of %d", page+1, (len(q.ActiveTracks)-1)/domain.QueuePageSize+1),
+					Value:  fmt.Sprintf("%d of %d", page+1, (len(q.ActiveTracks)-1)/g.QueuePageSize+1),
 					Inline: true,
 				},
 				{
@@ -95,12 +98,22 @@ func BuildQueueEmbed(p *domain.Player, q *domain.Queue, items []*domain.Music, p
 					Value:  cases.Title(language.English).String(q.Shuffle.String()),
 					Inline: true,
 				},
+				{
+					Name:   "Fair",
+					Value:  cases.Title(language.English).String(q.Fair.String()),
+					Inline: true,
+				},
+				{
+					Name:   "Autoplay",
+					Value:  cases.Title(language.English).String(q.Autoplay.String()),
+					Inline: true,
+				},
 			},
 		},
 	}
 }
 
-func BuildQueueComponent(p *domain.Player, q *domain.Queue, page int) []discordgo.MessageComponent {
+func BuildQueueComponent(p *domain.Player, q *domain.Queue, page int, g *domain.GuildSettings) []discordgo.MessageComponent {
 	prevBtn := discordgo.Button{
 		Emoji: discordgo.ComponentEmoji{Name: "⬅️"},
 		Label: "Previous Page",
@@ -115,7 +128,7 @@ func BuildQueueComponent(p *domain.Player, q *domain.Queue, page int) []discordg
 		Label: "Next Page",
 		Style: discordgo.SecondaryButton,
 		Disabled: p.Status == domain.PlayerStatusUninitialized || q.IsEmpty() ||
-			page == len(q.ActiveTracks)/domain.QueuePageSize,
+			page == len(q.ActiveTracks)/g.QueuePageSize,
 		CustomID: common.QueueComponentNextID,
 	}
 
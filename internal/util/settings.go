@@ -0,0 +1,27 @@
+package util
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+)
+
+func BuildSettingsEmbed(g *domain.GuildSettings) []*discordgo.MessageEmbed {
+	fields := make([]*discordgo.MessageEmbedField, 0, len(domain.SettingKeys))
+	for _, key := range domain.SettingKeys {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   string(key),
+			Value:  g.Value(key),
+			Inline: true,
+		})
+	}
+
+	return []*discordgo.MessageEmbed{
+		{
+			Title:  "Settings",
+			Color:  g.AccentColor(common.ColorBrand),
+			Fields: fields,
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package util
+
+import (
+	"strconv"
+)
+
+// ShardOf returns the shard that receives a guild's events.
+func ShardOf(guildID string, shardCount int) int {
+	if shardCount <= 1 {
+		return 0
+	}
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return int((id >> 22) % uint64(shardCount))
+}
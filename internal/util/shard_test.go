@@ -0,0 +1,31 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestShardOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		guildID    string
+		shardCount int
+		want       int
+	}{
+		{name: "single shard", guildID: "81384788765712384", shardCount: 1, want: 0},
+		{name: "unknown count", guildID: "81384788765712384", shardCount: 0, want: 0},
+		{name: "two shards", guildID: "81384788765712384", shardCount: 2, want: 0},
+		{name: "four shards", guildID: "81384788765712384", shardCount: 4, want: 2},
+		{name: "sixteen shards", guildID: "41771983423143937", shardCount: 16, want: 6},
+		{name: "low bits ignored", guildID: "41771983423143936", shardCount: 16, want: 6},
+		{name: "invalid id", guildID: "guild", shardCount: 4, want: 0},
+		{name: "empty id", guildID: "", shardCount: 4, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShardOf(tt.guildID, tt.shardCount); got != tt.want {
+				t.Errorf("ShardOf(%q, %d) = %d, want %d", tt.guildID, tt.shardCount, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/domain"
+)
+
+// VoiceStatusMaxLength is the longest voice channel status Discord accepts.
+const VoiceStatusMaxLength = 500
+
+// SetVoiceChannelStatus sets the status shown under a voice channel, an empty status clears it.
+// It needs the Set Voice Channel Status permission, and is not yet supported by discordgo.
+func SetVoiceChannelStatus(s *discordgo.Session, channelID, status string) error {
+	endpoint := discordgo.EndpointChannel(channelID) + "/voice-status"
+	_, err := s.RequestWithBucketID("PUT", endpoint, map[string]string{"status": status}, endpoint)
+	return err
+}
+
+// SetStageSpeaker unsuppresses the bot in a Stage channel it has joined, which needs the Stage moderator permissions.
+// Without them, it requests to speak instead and returns domain.ErrStageModerator.
+func SetStageSpeaker(s *discordgo.Session, guildID, channelID string) error {
+	endpoint := discordgo.EndpointGuild(guildID) + "/voice-states/@me"
+	_, err := s.RequestWithBucketID("PATCH", endpoint, map[string]interface{}{
+		"channel_id": channelID,
+		"suppress":   false,
+	}, endpoint)
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil || restErr.Response.StatusCode != http.StatusForbidden {
+		return err
+	}
+
+	_, err = s.RequestWithBucketID("PATCH", endpoint, map[string]interface{}{
+		"channel_id":                 channelID,
+		"request_to_speak_timestamp": time.Now().UTC().Format(time.RFC3339),
+	}, endpoint)
+	if err != nil {
+		return err
+	}
+	return domain.ErrStageModerator
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/daystram/caroline/internal/common"
+	"github.com/daystram/caroline/internal/domain"
+)
+
+func BuildVoteSkipEmbed(music *domain.Music, votes, required int) []*discordgo.MessageEmbed {
+	title := music.Query
+	if music.Loaded {
+		title = music.Title
+	}
+
+	return []*discordgo.MessageEmbed{
+		{
+			Title:       "Vote Skip",
+			Description: title,
+			Color:       common.ColorAction,
+			Fields: []*discordgo.MessageEmbedField{
+				{
+					Name:   "Votes",
+					Value:  fmt.Sprintf("%d of %d", votes, required),
+					Inline: true,
+				},
+			},
+		},
+	}
+}
+
+// BuildVoteSkipComponent builds the vote button for music, so that votes are not counted once it is no longer playing.
+func BuildVoteSkipComponent(music *domain.Music) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Emoji:    discordgo.ComponentEmoji{Name: "⏭"},
+					Label:    "Vote skip",
+					Style:    discordgo.PrimaryButton,
+					CustomID: ComponentID(common.VoteComponentSkipID, music.ID),
+				},
+			},
+		},
+	}
+}